@@ -0,0 +1,202 @@
+package bitbadger
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// bbTokenURL is the endpoint issuing OAuth access tokens.
+const bbTokenURL = "https://bitbucket.org/site/oauth2/access_token"
+
+// tokenRefreshMargin is how long before their expiry OAuth access tokens are
+// refreshed, so that they don't expire during a request.
+const tokenRefreshMargin = time.Minute
+
+// authenticator adds credentials to requests sent to BitBucket.
+type authenticator interface {
+	// authenticate adds the credentials to req.
+	authenticate(ctx context.Context, req *http.Request) error
+	// invalidate discards the credentials of req once rejected by BitBucket,
+	// and returns true if the request can be authenticated again with new
+	// credentials.
+	invalidate(req *http.Request) bool
+	// account identifies the account which requests are counted against,
+	// for rate limiting.
+	account() string
+}
+
+// newAuthenticator returns the authenticator of the method selected in
+// config. OAuth access tokens are requested with client.
+func newAuthenticator(config Config, client *http.Client) authenticator {
+	switch config.Auth {
+	case OAuthAuth:
+		return newOAuthAuthenticator(config, client, bbTokenURL)
+	case AccessTokenAuth:
+		return accessTokenAuthenticator{token: config.AccessToken}
+	default:
+		return appPasswordAuthenticator{username: config.Username, password: config.Password}
+	}
+}
+
+// appPasswordAuthenticator authenticates with HTTP basic authentication.
+type appPasswordAuthenticator struct {
+	username string
+	password string
+}
+
+func (auth appPasswordAuthenticator) authenticate(ctx context.Context, req *http.Request) error {
+	req.SetBasicAuth(auth.username, auth.password)
+	return nil
+}
+
+func (auth appPasswordAuthenticator) invalidate(req *http.Request) bool {
+	return false
+}
+
+func (auth appPasswordAuthenticator) account() string {
+	return auth.username
+}
+
+// accessTokenAuthenticator authenticates with a bearer token which doesn't
+// expire, such as a repository or workspace access token.
+type accessTokenAuthenticator struct {
+	token string
+}
+
+func (auth accessTokenAuthenticator) authenticate(ctx context.Context, req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+auth.token)
+	return nil
+}
+
+func (auth accessTokenAuthenticator) invalidate(req *http.Request) bool {
+	return false
+}
+
+// account identifies the token by its hash, so that it doesn't leak through
+// the rate limiter.
+func (auth accessTokenAuthenticator) account() string {
+	hash := sha256.Sum256([]byte(auth.token))
+	return fmt.Sprintf("access-token:%x", hash[:8])
+}
+
+// oauthAuthenticator authenticates as an OAuth consumer, with access tokens
+// obtained through the client credentials grant. The token is shared by
+// concurrent requests, and refreshed by the first request needing it while
+// the others wait.
+type oauthAuthenticator struct {
+	clientID     string
+	clientSecret string
+	client       *http.Client
+	tokenURL     string
+
+	mutex       sync.Mutex
+	accessToken string
+	expiry      time.Time
+}
+
+func newOAuthAuthenticator(config Config, client *http.Client, tokenURL string) *oauthAuthenticator {
+	return &oauthAuthenticator{
+		clientID:     config.ClientID,
+		clientSecret: config.ClientSecret,
+		client:       client,
+		tokenURL:     tokenURL,
+	}
+}
+
+func (auth *oauthAuthenticator) authenticate(ctx context.Context, req *http.Request) error {
+	token, err := auth.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// invalidate discards the access token of req, unless it was already
+// replaced by a concurrent request.
+func (auth *oauthAuthenticator) invalidate(req *http.Request) bool {
+	auth.mutex.Lock()
+	defer auth.mutex.Unlock()
+
+	if auth.accessToken != "" && req.Header.Get("Authorization") == "Bearer "+auth.accessToken {
+		auth.accessToken = ""
+	}
+
+	return true
+}
+
+func (auth *oauthAuthenticator) account() string {
+	return "oauth:" + auth.clientID
+}
+
+// token returns the current access token, requesting a new one if it is
+// missing or about to expire.
+func (auth *oauthAuthenticator) token(ctx context.Context) (string, error) {
+	auth.mutex.Lock()
+	defer auth.mutex.Unlock()
+
+	if auth.accessToken != "" && time.Until(auth.expiry) > tokenRefreshMargin {
+		return auth.accessToken, nil
+	}
+
+	start := time.Now()
+	token, expiresIn, err := auth.requestToken(ctx)
+	observeUpstreamRequest("bbcloud", "access_token", time.Since(start), err)
+	if err != nil {
+		return "", err
+	}
+
+	log.Debug("Obtained an OAuth access token, expiring in ", expiresIn)
+	auth.accessToken = token
+	auth.expiry = start.Add(expiresIn)
+	return token, nil
+}
+
+// requestToken requests an access token with the client credentials grant.
+func (auth *oauthAuthenticator) requestToken(ctx context.Context) (string, time.Duration, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	req, err := http.NewRequest("POST", auth.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(auth.clientID, auth.clientSecret)
+
+	resp, err := auth.client.Do(req.WithContext(ctx))
+	if err != nil {
+		log.Error("OAuth token request failed: ", err)
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Error("Non-200 OAuth token response: ", resp.Status)
+		return "", 0, newUpstreamError(resp)
+	}
+
+	var response struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		log.Error("Failed to decode OAuth token response: ", err)
+		return "", 0, err
+	}
+	if response.AccessToken == "" {
+		return "", 0, errors.New("OAuth token response has no access token")
+	}
+
+	return response.AccessToken, time.Duration(response.ExpiresIn) * time.Second, nil
+}
@@ -0,0 +1,160 @@
+package bitbadger
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAuthenticators(t *testing.T) {
+	cases := []struct {
+		config        Config
+		authorization string
+		account       string
+	}{
+		{Config{Username: "user", Password: "secret"}, "Basic dXNlcjpzZWNyZXQ=", "user"},
+		{Config{Auth: AppPasswordAuth, Username: "user", Password: "secret"}, "Basic dXNlcjpzZWNyZXQ=", "user"},
+		{Config{Auth: AccessTokenAuth, AccessToken: "token"}, "Bearer token", "access-token:3c469e9d6c5875d3"},
+	}
+
+	for _, c := range cases {
+		auth := newAuthenticator(c.config, nil)
+
+		req, _ := http.NewRequest("GET", bbAPIURL, nil)
+		err := auth.authenticate(context.Background(), req)
+		if err != nil {
+			t.Fatalf("authenticate: Unexpected error: %s", err)
+		}
+		if authorization := req.Header.Get("Authorization"); authorization != c.authorization {
+			t.Errorf("authenticate: Expected '%s' for %s, got '%s'", c.authorization, c.config.Auth, authorization)
+		}
+		if account := auth.account(); account != c.account {
+			t.Errorf("account: Expected '%s' for %s, got '%s'", c.account, c.config.Auth, account)
+		}
+		if auth.invalidate(req) {
+			t.Errorf("invalidate: Credentials of %s can't be renewed", c.config.Auth)
+		}
+	}
+}
+
+// newOAuthUpstream returns a BitBucket server issuing access tokens, and the
+// number of tokens issued. Tokens issued before revokeBefore are rejected.
+func newOAuthUpstream(revokeBefore *int32) (*httptest.Server, *int32) {
+	var issued int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			clientID, clientSecret, _ := r.BasicAuth()
+			if r.Method != "POST" || r.FormValue("grant_type") != "client_credentials" ||
+				clientID != "consumer" || clientSecret != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			token := atomic.AddInt32(&issued, 1)
+			fmt.Fprintf(w, `{"access_token": "token-%d", "token_type": "bearer", "expires_in": 7200}`, token)
+			return
+		}
+
+		var token int32
+		fmt.Sscanf(r.Header.Get("Authorization"), "Bearer token-%d", &token)
+		if token == 0 || token < atomic.LoadInt32(revokeBefore) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"values": []}`)
+	}))
+
+	return upstream, &issued
+}
+
+func newOAuthBBCloud(upstream *httptest.Server) *BBCloud {
+	config := Config{Auth: OAuthAuth, ClientID: "consumer", ClientSecret: "secret"}
+	bb := NewBBCloud(config, upstream.Client(), WithBBRateLimiter(NewRateLimiter(RateLimitPolicy{})))
+	bb.apiURL = upstream.URL + "/"
+	bb.auth = newOAuthAuthenticator(config, upstream.Client(), upstream.URL+"/token")
+	return bb
+}
+
+func TestOAuthAuthenticator(t *testing.T) {
+
+	var revokeBefore int32
+	upstream, issued := newOAuthUpstream(&revokeBefore)
+	defer upstream.Close()
+
+	bb := newOAuthBBCloud(upstream)
+
+	// Concurrent requests share a single token.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := bb.RetrieveRepositories(context.Background(), BadgeRequest{Username: "team"})
+			if err != nil {
+				t.Errorf("RetrieveRepositories: Unexpected error: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if *issued != 1 {
+		t.Errorf("oauthAuthenticator: Expected 1 token, got %d", *issued)
+	}
+
+	// Tokens are refreshed before they expire.
+	auth := bb.auth.(*oauthAuthenticator)
+	auth.expiry = time.Now().Add(tokenRefreshMargin / 2)
+	_, err := bb.RetrieveRepositories(context.Background(), BadgeRequest{Username: "team"})
+	if err != nil {
+		t.Errorf("RetrieveRepositories: Unexpected error: %s", err)
+	}
+	if *issued != 2 {
+		t.Errorf("oauthAuthenticator: Expected the token to be refreshed, got %d tokens", *issued)
+	}
+
+	// Revoked tokens are replaced.
+	atomic.StoreInt32(&revokeBefore, 3)
+	_, err = bb.RetrieveRepositories(context.Background(), BadgeRequest{Username: "team"})
+	if err != nil {
+		t.Errorf("RetrieveRepositories: Unexpected error: %s", err)
+	}
+	if *issued != 3 {
+		t.Errorf("oauthAuthenticator: Expected the revoked token to be replaced, got %d tokens", *issued)
+	}
+
+	// A token rejected again fails the request.
+	atomic.StoreInt32(&revokeBefore, 100)
+	_, err = bb.RetrieveRepositories(context.Background(), BadgeRequest{Username: "team"})
+	upstreamErr, ok := err.(*UpstreamError)
+	if !ok || upstreamErr.Kind != UpstreamUnauthorized {
+		t.Errorf("RetrieveRepositories: Expected an unauthorized error, got %v", err)
+	}
+	if *issued != 4 {
+		t.Errorf("oauthAuthenticator: Expected a single new token, got %d tokens", *issued)
+	}
+}
+
+func TestOAuthAuthenticatorInvalidConsumer(t *testing.T) {
+	var revokeBefore int32
+	upstream, _ := newOAuthUpstream(&revokeBefore)
+	defer upstream.Close()
+
+	auth := newOAuthAuthenticator(Config{Auth: OAuthAuth, ClientID: "consumer", ClientSecret: "wrong"},
+		upstream.Client(), upstream.URL+"/token")
+
+	req, _ := http.NewRequest("GET", upstream.URL, nil)
+	err := auth.authenticate(context.Background(), req)
+	upstreamErr, ok := err.(*UpstreamError)
+	if !ok || upstreamErr.Kind != UpstreamUnauthorized {
+		t.Errorf("authenticate: Expected an unauthorized error, got %v", err)
+	}
+	if strings.Contains(req.Header.Get("Authorization"), "Bearer") {
+		t.Errorf("authenticate: No token should be set")
+	}
+}
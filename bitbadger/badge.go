@@ -0,0 +1,253 @@
+package bitbadger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// BadgeInfo holds information required to generate a badge image.
+type BadgeInfo struct {
+	Label   string
+	Message string
+	Color   string
+}
+
+// Segments returns the segments of the badge: its label and its message.
+func (badge BadgeInfo) Segments() []BadgeSegment {
+	return []BadgeSegment{
+		{Text: badge.Label, Color: labelColor},
+		{Text: badge.Message, Color: badge.Color},
+	}
+}
+
+// BadgeImage holds the badge image data and extension.
+type BadgeImage struct {
+	Data      []byte
+	Extension string
+}
+
+// MetricsReport holds a badge and the metrics it was generated from.
+type MetricsReport struct {
+	Badge   BadgeInfo
+	Metrics interface{}
+}
+
+// GenerateBadge generates a badge from a BadgeRequest, using the global
+// configuration.
+func GenerateBadge(request BadgeRequest) (*BadgeImage, error) {
+	return defaultServer().GenerateBadge(context.Background(), request)
+}
+
+// GenerateBadge generates a badge from a BadgeRequest.
+func (server *Server) GenerateBadge(ctx context.Context, request BadgeRequest) (*BadgeImage, error) {
+	if request.JSON {
+		return server.GenerateMetricsReport(ctx, request)
+	}
+
+	metrics, badge, err := server.retrieveBadgeInfo(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	segments := badge.Segments()
+	if summaryInfo, isSummary := metrics.(SummaryInfo); isSummary {
+		segments = summaryInfo.Segments()
+	}
+
+	start := time.Now()
+	badgeImage, err := server.renderer.Render(ctx, segments)
+	renderDuration.observe(time.Since(start).Seconds())
+	if err != nil {
+		log.Error("Error rendering badge: ", err)
+		return nil, errors.New("Failed to render badge")
+	}
+
+	return badgeImage, nil
+}
+
+// GenerateMetricsReport generates a JSON document holding the badge
+// information and the metrics it was generated from, using the global
+// configuration.
+func GenerateMetricsReport(request BadgeRequest) (*BadgeImage, error) {
+	return defaultServer().GenerateMetricsReport(context.Background(), request)
+}
+
+// GenerateMetricsReport generates a JSON document holding the badge
+// information and the metrics it was generated from.
+func (server *Server) GenerateMetricsReport(ctx context.Context, request BadgeRequest) (*BadgeImage, error) {
+	metrics, badge, err := server.retrieveBadgeInfo(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(MetricsReport{
+		Badge:   badge,
+		Metrics: metrics,
+	})
+	if err != nil {
+		log.Error("Failed to encode metrics: ", err)
+		return nil, errors.New("Failed to encode metrics")
+	}
+
+	return &BadgeImage{
+		Data:      data,
+		Extension: "json",
+	}, nil
+}
+
+// retrieveBadgeInfo retrieves the metrics required by the request, and
+// generates the badge information from them.
+func (server *Server) retrieveBadgeInfo(ctx context.Context, request BadgeRequest) (interface{}, BadgeInfo, error) {
+	metrics, err := server.RetrieveMetrics(ctx, request)
+	if err != nil {
+		return nil, BadgeInfo{}, err
+	}
+
+	badge, err := checkBadgeInfo(server.factory.metricsBadgeInfo(request.Type, metrics, server.requestLocalizer(request)))
+	if err != nil {
+		return nil, BadgeInfo{}, err
+	}
+
+	return metrics, badge, nil
+}
+
+// RetrieveMetrics retrieves the upstream data required by the request badge
+// type, using the global configuration.
+func RetrieveMetrics(request BadgeRequest) (interface{}, error) {
+	return defaultServer().RetrieveMetrics(context.Background(), request)
+}
+
+// RetrieveMetrics retrieves the upstream data required by the request badge
+// type. Depending on the type, it is a PullRequestsInfo, BuildInfo,
+// CommitsInfo, BranchesInfo, IssuesInfo, TagsInfo, HealthInfo or
+// SummaryInfo.
+func (server *Server) RetrieveMetrics(ctx context.Context, request BadgeRequest) (interface{}, error) {
+	var metrics interface{}
+	var err error
+
+	kind := metricsKind(request.Type)
+	switch kind {
+	case "build":
+		metrics, err = server.provider.RetrieveBuildInfo(ctx, request)
+	case "commits":
+		metrics, err = server.provider.RetrieveCommitsInfo(ctx, request)
+	case "branches":
+		metrics, err = server.provider.RetrieveBranchesInfo(ctx, request)
+	case "issues":
+		metrics, err = server.provider.RetrieveIssuesInfo(ctx, request)
+	case "tags":
+		metrics, err = server.provider.RetrieveTagsInfo(ctx, request)
+	case "summary":
+		// Upstream errors of the parts are logged and hidden as for other
+		// badges, see metricsError. Invalid parts return their own error.
+		return server.retrieveSummaryInfo(ctx, request)
+	default:
+		var prInfo PullRequestsInfo
+		prInfo, err = server.retrievePullRequestInfo(ctx, request)
+		metrics = server.pullRequestsMetrics(request.Type, prInfo)
+	}
+
+	if err != nil {
+		return nil, metricsError(kind, err)
+	}
+
+	return metrics, nil
+}
+
+// retrievePullRequestInfo retrieves the pull request information of the
+// request repository, or of all the repositories of its workspace.
+func (server *Server) retrievePullRequestInfo(ctx context.Context, request BadgeRequest) (PullRequestsInfo, error) {
+	if request.Repository == WorkspaceRepositories {
+		return server.retrieveWorkspacePullRequestInfo(ctx, request)
+	}
+
+	return server.provider.RetrievePullRequestInfo(ctx, request)
+}
+
+// metricsError logs an error retrieving a kind of metrics, and returns a
+// generic error hiding its details. Errors which are served with a dedicated
+// badge are returned as-is.
+func metricsError(kind string, err error) error {
+	if err == ErrCircuitOpen || err == ErrNoCredentials {
+		return err
+	}
+
+	log.Error("Error while retrieving badge info: ", err)
+	return errors.New("Error while getting " + kind + " info from the upstream server")
+}
+
+// metricsKind returns the kind of upstream data required by a badge type.
+func metricsKind(badgeType BadgeType) string {
+	switch badgeType {
+	case BuildStatusType, PRBuildPassRateType:
+		return "build"
+	case LastCommitType, CommitActivityType, ContributorsType, BusFactorType:
+		return "commits"
+	case StaleBranchesType:
+		return "branches"
+	case OpenIssueCountType, OldestOpenIssueAgeType:
+		return "issues"
+	case LatestTagType:
+		return "tags"
+	case SummaryType:
+		return "summary"
+	default:
+		return "pull request"
+	}
+}
+
+// pullRequestsMetrics returns the metrics of a badge type derived from pull
+// request information.
+func (server *Server) pullRequestsMetrics(badgeType BadgeType, prInfo PullRequestsInfo) interface{} {
+	if badgeType == HealthType {
+		return computeHealth(prInfo, server.healthPolicy)
+	}
+
+	return prInfo
+}
+
+// GenerateMetricsBadgeInfo generates a badge from a type and the metrics
+// returned by RetrieveMetrics.
+func GenerateMetricsBadgeInfo(badgeType BadgeType, metrics interface{}, localizer Localizer) (BadgeInfo, error) {
+	return defaultBadgeFactory().metricsBadgeInfo(badgeType, metrics, localizer)
+}
+
+// metricsBadgeInfo generates a badge from a type and the metrics returned by
+// RetrieveMetrics.
+func (factory badgeFactory) metricsBadgeInfo(badgeType BadgeType, metrics interface{}, localizer Localizer) (BadgeInfo, error) {
+	switch info := metrics.(type) {
+	case PullRequestsInfo:
+		return factory.pullRequestsBadgeInfo(badgeType, info, localizer)
+	case BuildInfo:
+		return GenerateBuildBadgeInfo(badgeType, info, localizer)
+	case CommitsInfo:
+		return factory.commitsBadgeInfo(badgeType, info, localizer)
+	case BranchesInfo:
+		return GenerateBranchesBadgeInfo(badgeType, info, localizer)
+	case IssuesInfo:
+		return factory.issuesBadgeInfo(badgeType, info, localizer)
+	case TagsInfo:
+		return GenerateTagsBadgeInfo(badgeType, info, localizer)
+	case HealthInfo:
+		return GenerateHealthBadgeInfo(badgeType, info, localizer)
+	case SummaryInfo:
+		return GenerateSummaryBadgeInfo(badgeType, info)
+	default:
+		return BadgeInfo{}, errors.New("Invalid metrics")
+	}
+}
+
+// checkBadgeInfo logs badge generation errors, and replaces them with a
+// generic error suitable for clients.
+func checkBadgeInfo(badge BadgeInfo, err error) (BadgeInfo, error) {
+	if err != nil {
+		log.Error("Failed to generate badge: ", err)
+		return BadgeInfo{}, errors.New("Failed to generate badge")
+	}
+
+	return badge, nil
+}
@@ -0,0 +1,38 @@
+package bitbadger
+
+import (
+	"testing"
+)
+
+func TestGenerateMetricsBadgeInfo(t *testing.T) {
+	cases := []struct {
+		inType        BadgeType
+		inMetrics     interface{}
+		expectedLabel string
+	}{
+		{OpenPRCountType, PullRequestsInfo{}, "Open PRs"},
+		{BuildStatusType, BuildInfo{}, "Build"},
+		{LastCommitType, CommitsInfo{}, "Last commit"},
+		{StaleBranchesType, BranchesInfo{}, "Stale branches"},
+	}
+
+	for _, c := range cases {
+		badgeInfo, err := GenerateMetricsBadgeInfo(c.inType, c.inMetrics, DefaultLocalizer())
+		if err != nil {
+			t.Errorf("GenerateMetricsBadgeInfo: Unexpected error for %s: %s", c.inType, err)
+		}
+		if badgeInfo.Label != c.expectedLabel {
+			t.Errorf("GenerateMetricsBadgeInfo: Incorrect label for %s: %s", c.inType, badgeInfo.Label)
+		}
+	}
+
+	_, err := GenerateMetricsBadgeInfo(OpenPRCountType, BuildInfo{}, DefaultLocalizer())
+	if err == nil {
+		t.Errorf("Should generate an error for mismatching metrics")
+	}
+
+	_, err = GenerateMetricsBadgeInfo(OpenPRCountType, nil, DefaultLocalizer())
+	if err == nil {
+		t.Errorf("Should generate an error for missing metrics")
+	}
+}
@@ -0,0 +1,241 @@
+package bitbadger
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BadgeType represents the type of badge / metric to show.
+type BadgeType string
+
+const (
+	// OpenPRCountType shows the number of open PRs.
+	OpenPRCountType BadgeType = "open-pr-count"
+	// OpenPRAverageAgeType shows the average age of currently open PRs.
+	OpenPRAverageAgeType BadgeType = "open-pr-avg-age"
+	// OldestOpenPRAge shows the age of the oldest currently open PR.
+	OldestOpenPRAge BadgeType = "oldest-open-pr-age"
+	// AveragePRMergeTime shows average merge time of recent PRs.
+	AveragePRMergeTime BadgeType = "avg-pr-merge-time"
+	// DraftPRCountType shows the number of open draft PRs.
+	DraftPRCountType BadgeType = "draft-pr-count"
+	// AwaitingReviewCountType shows the number of open PRs without any review.
+	AwaitingReviewCountType BadgeType = "awaiting-review-count"
+	// AverageReviewersType shows the average number of reviewers of open PRs.
+	AverageReviewersType BadgeType = "avg-reviewers-per-pr"
+	// PendingReviewsType shows the number of open PRs waiting on a review
+	// from a specific user.
+	PendingReviewsType BadgeType = "pending-reviews"
+	// MergedPRsPerDayType shows the number of PRs merged per day.
+	MergedPRsPerDayType BadgeType = "merged-prs-per-day"
+	// MergedPRsPerWeekType shows the number of PRs merged per week.
+	MergedPRsPerWeekType BadgeType = "merged-prs-per-week"
+	// MergedPRsPerMonthType shows the number of PRs merged per month.
+	MergedPRsPerMonthType BadgeType = "merged-prs-per-month"
+)
+
+// badgeTypes lists all the valid badge types.
+var badgeTypes = []BadgeType{
+	OpenPRCountType,
+	OpenPRAverageAgeType,
+	OldestOpenPRAge,
+	AveragePRMergeTime,
+	DraftPRCountType,
+	AwaitingReviewCountType,
+	AverageReviewersType,
+	PendingReviewsType,
+	MergedPRsPerDayType,
+	MergedPRsPerWeekType,
+	MergedPRsPerMonthType,
+	BuildStatusType,
+	PRBuildPassRateType,
+	LastCommitType,
+	CommitActivityType,
+	ContributorsType,
+	BusFactorType,
+	StaleBranchesType,
+	OpenIssueCountType,
+	OldestOpenIssueAgeType,
+	LatestTagType,
+	HealthType,
+	SummaryType,
+}
+
+// GetBadgeType returns a BadgeType from a string, and an error if there is no
+//  corresponding BadgeType.
+func GetBadgeType(badgeString string) (BadgeType, error) {
+	badgeType := BadgeType(badgeString)
+	if BadgeTypeValid(badgeType) {
+		return badgeType, nil
+	}
+
+	validTypes := make([]string, 0, len(badgeTypes))
+	for _, validType := range badgeTypes {
+		validTypes = append(validTypes, string(validType))
+	}
+
+	return badgeType, errors.New("Invalid badge type '" + badgeString + "'." +
+		"Badge type can be one of '" + strings.Join(validTypes, "', '") + "'.")
+}
+
+// BadgeTypeValid returns true if the BadgeType provided is valid, false
+// otherwise.
+func BadgeTypeValid(badgeType BadgeType) bool {
+	for _, validType := range badgeTypes {
+		if badgeType == validType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GenerateBadgeInfo generates a badge from a type and pull request
+// information.
+func GenerateBadgeInfo(badgeType BadgeType, prInfo PullRequestsInfo) (BadgeInfo, error) {
+	return GenerateLocalizedBadgeInfo(badgeType, prInfo, DefaultLocalizer())
+}
+
+// GenerateLocalizedBadgeInfo generates a badge from a type and pull request
+// information, with texts and durations from localizer.
+func GenerateLocalizedBadgeInfo(badgeType BadgeType, prInfo PullRequestsInfo, localizer Localizer) (BadgeInfo, error) {
+	return defaultBadgeFactory().pullRequestsBadgeInfo(badgeType, prInfo, localizer)
+}
+
+// badgeFactory generates badges according to the policies of a server.
+type badgeFactory struct {
+	thresholds ThresholdPolicy
+	// workHours marks the labels of durations counted in working hours.
+	workHours bool
+}
+
+// defaultBadgeFactory returns the factory using the global policies, used by
+// the package level functions.
+func defaultBadgeFactory() badgeFactory {
+	return badgeFactory{
+		thresholds: thresholdPolicy,
+		workHours:  businessHoursPolicy.Enabled,
+	}
+}
+
+// pullRequestsBadgeInfo generates a badge from a type and pull request
+// information.
+func (factory badgeFactory) pullRequestsBadgeInfo(badgeType BadgeType, prInfo PullRequestsInfo, localizer Localizer) (BadgeInfo, error) {
+	switch badgeType {
+	case OpenPRCountType:
+		return factory.generateOpenPRCountBadge(prInfo, localizer), nil
+	case OpenPRAverageAgeType:
+		return factory.generateAveragePRTimeBadge(prInfo, localizer), nil
+	case OldestOpenPRAge:
+		return factory.generateOldestOpenPRAgeBadge(prInfo, localizer), nil
+	case AveragePRMergeTime:
+		return factory.generateAveragePRMergeTimeBadge(prInfo, localizer), nil
+	case DraftPRCountType:
+		return generateDraftPRCountBadge(prInfo, localizer), nil
+	case AwaitingReviewCountType:
+		return factory.generateAwaitingReviewCountBadge(prInfo, localizer), nil
+	case AverageReviewersType:
+		return factory.generateAverageReviewersBadge(prInfo, localizer), nil
+	case PendingReviewsType:
+		return factory.generatePendingReviewsBadge(prInfo, localizer), nil
+	case MergedPRsPerDayType:
+		return factory.generateMergedPRsRateBadge(prInfo, 24*time.Hour, "day", localizer), nil
+	case MergedPRsPerWeekType:
+		return factory.generateMergedPRsRateBadge(prInfo, 7*24*time.Hour, "week", localizer), nil
+	case MergedPRsPerMonthType:
+		return factory.generateMergedPRsRateBadge(prInfo, 30*24*time.Hour, "month", localizer), nil
+	default:
+		return BadgeInfo{}, errors.New("Invalid badge type")
+	}
+}
+
+func (factory badgeFactory) generateOpenPRCountBadge(prInfo PullRequestsInfo, localizer Localizer) BadgeInfo {
+	return BadgeInfo{
+		Label:   localizer.Text("label.open-prs"),
+		Message: strconv.Itoa(prInfo.OpenCount),
+		Color:   factory.openCountColor(prInfo.OpenCount),
+	}
+}
+
+func (factory badgeFactory) openCountColor(openCount int) string {
+	return countColor(openCount, factory.thresholds.OpenPRCount)
+}
+
+func generateDraftPRCountBadge(prInfo PullRequestsInfo, localizer Localizer) BadgeInfo {
+	// Drafts are informative only, they are not a sign of bad health.
+	return BadgeInfo{
+		Label:   localizer.Text("label.draft-prs"),
+		Message: strconv.Itoa(prInfo.DraftCount),
+		Color:   "lightgrey",
+	}
+}
+
+func (factory badgeFactory) generateAwaitingReviewCountBadge(prInfo PullRequestsInfo, localizer Localizer) BadgeInfo {
+	return BadgeInfo{
+		Label:   localizer.Text("label.awaiting-review"),
+		Message: strconv.Itoa(prInfo.AwaitingReviewCount),
+		Color:   factory.reviewCountColor(prInfo.AwaitingReviewCount),
+	}
+}
+
+func (factory badgeFactory) generateAverageReviewersBadge(prInfo PullRequestsInfo, localizer Localizer) BadgeInfo {
+	return BadgeInfo{
+		Label:   localizer.Text("label.avg-reviewers"),
+		Message: localizer.Number(prInfo.AverageReviewers, 1),
+		Color:   rateColor(prInfo.AverageReviewers, factory.thresholds.Reviewers),
+	}
+}
+
+func (factory badgeFactory) generatePendingReviewsBadge(prInfo PullRequestsInfo, localizer Localizer) BadgeInfo {
+	return BadgeInfo{
+		Label:   localizer.Text("label.pending-reviews"),
+		Message: strconv.Itoa(prInfo.PendingReviewCount),
+		Color:   factory.reviewCountColor(prInfo.PendingReviewCount),
+	}
+}
+
+func (factory badgeFactory) reviewCountColor(count int) string {
+	return countColor(count, factory.thresholds.ReviewCount)
+}
+
+func (factory badgeFactory) generateMergedPRsRateBadge(prInfo PullRequestsInfo, period time.Duration, unit string, localizer Localizer) BadgeInfo {
+	rate := throughputRate(prInfo.MergedCount, prInfo.MergedWindow, period)
+
+	// Colors are chosen from the weekly rate, whatever the unit displayed.
+	weeklyRate := throughputRate(prInfo.MergedCount, prInfo.MergedWindow, 7*24*time.Hour)
+	return BadgeInfo{
+		Label:   localizer.Text("label.merged-prs"),
+		Message: printRate(rate, unit, localizer),
+		Color:   rateColor(weeklyRate, factory.thresholds.MergedPRs),
+	}
+}
+
+func (factory badgeFactory) generateAveragePRTimeBadge(prInfo PullRequestsInfo, localizer Localizer) BadgeInfo {
+	return BadgeInfo{
+		Label:   factory.durationLabel("label.open-pr-avg-age", localizer),
+		Message: localizer.Duration(prInfo.OpenAverageTime),
+		Color:   factory.openTimeColor(prInfo.OpenAverageTime),
+	}
+}
+
+func (factory badgeFactory) generateOldestOpenPRAgeBadge(prInfo PullRequestsInfo, localizer Localizer) BadgeInfo {
+	return BadgeInfo{
+		Label:   factory.durationLabel("label.oldest-open-pr-age", localizer),
+		Message: localizer.Duration(prInfo.OldestOpenPR),
+		Color:   factory.openTimeColor(prInfo.OldestOpenPR),
+	}
+}
+
+func (factory badgeFactory) generateAveragePRMergeTimeBadge(prInfo PullRequestsInfo, localizer Localizer) BadgeInfo {
+	return BadgeInfo{
+		Label:   factory.durationLabel("label.avg-pr-merge-time", localizer),
+		Message: localizer.Duration(prInfo.AveragePRMergeTime),
+		Color:   factory.openTimeColor(prInfo.AveragePRMergeTime),
+	}
+}
+
+func (factory badgeFactory) openTimeColor(openTime time.Duration) string {
+	return durationColor(openTime, factory.thresholds.PRAge)
+}
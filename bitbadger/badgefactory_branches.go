@@ -0,0 +1,85 @@
+package bitbadger
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+const (
+	// StaleBranchesType shows the number of stale branches, which have no
+	// recent commit and no open PR.
+	StaleBranchesType BadgeType = "stale-branches"
+)
+
+// StaleBranchPolicy holds the settings used to detect stale branches.
+type StaleBranchPolicy struct {
+	// Age is the age of the tip commit after which a branch without open PR
+	// is stale, unless overridden by the request.
+	Age time.Duration
+}
+
+// DefaultStaleBranchAge is the default age after which branches are stale.
+const DefaultStaleBranchAge = 90 * 24 * time.Hour
+
+var staleBranchPolicy = StaleBranchPolicy{
+	Age: DefaultStaleBranchAge,
+}
+
+// SetStaleBranchPolicy sets the global stale branch policy.
+func SetStaleBranchPolicy(policy StaleBranchPolicy) {
+	staleBranchPolicy = policy
+}
+
+// GetStaleBranchPolicy returns the current global stale branch policy.
+func GetStaleBranchPolicy() StaleBranchPolicy {
+	return staleBranchPolicy
+}
+
+// requestAge returns the stale branch age to use for request, which can
+// override the policy age with a number of days.
+func (policy StaleBranchPolicy) requestAge(request BadgeRequest) time.Duration {
+	if request.Days > 0 {
+		return time.Duration(request.Days) * 24 * time.Hour
+	}
+
+	if policy.Age <= 0 {
+		return DefaultStaleBranchAge
+	}
+
+	return policy.Age
+}
+
+// GenerateBranchesBadgeInfo generates a badge from a type and branches
+// information.
+func GenerateBranchesBadgeInfo(badgeType BadgeType, branchesInfo BranchesInfo, localizer Localizer) (BadgeInfo, error) {
+	switch badgeType {
+	case StaleBranchesType:
+		return generateStaleBranchesBadge(branchesInfo, localizer), nil
+	default:
+		return BadgeInfo{}, errors.New("Invalid badge type")
+	}
+}
+
+func generateStaleBranchesBadge(branchesInfo BranchesInfo, localizer Localizer) (badge BadgeInfo) {
+	staleCount := len(branchesInfo.StaleBranches)
+	badge = BadgeInfo{
+		Label:   localizer.Text("label.stale-branches"),
+		Message: strconv.Itoa(staleCount),
+	}
+
+	switch {
+	case staleCount <= 5:
+		badge.Color = "green"
+	case staleCount <= 15:
+		badge.Color = "yellowgreen"
+	case staleCount <= 30:
+		badge.Color = "yellow"
+	case staleCount <= 50:
+		badge.Color = "orange"
+	default:
+		badge.Color = "red"
+	}
+
+	return
+}
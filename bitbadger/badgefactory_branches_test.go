@@ -0,0 +1,53 @@
+package bitbadger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGenerateBranchesBadgeInfo(t *testing.T) {
+	cases := []struct {
+		inInfo          BranchesInfo
+		expectedMessage string
+		expectedColor   string
+	}{
+		{BranchesInfo{}, "0", "green"},
+		{BranchesInfo{StaleBranches: make([]string, 20)}, "20", "yellow"},
+		{BranchesInfo{StaleBranches: make([]string, 51)}, "51", "red"},
+	}
+
+	for _, c := range cases {
+		badgeInfo, _ := GenerateBranchesBadgeInfo(StaleBranchesType, c.inInfo, DefaultLocalizer())
+
+		if badgeInfo.Label != "Stale branches" {
+			t.Errorf("Incorrect label for StaleBranchesType: %s", badgeInfo.Label)
+		}
+		if badgeInfo.Message != c.expectedMessage {
+			t.Errorf("Incorrect message for StaleBranchesType: %s", badgeInfo.Message)
+		}
+		if badgeInfo.Color != c.expectedColor {
+			t.Errorf("Incorrect color for StaleBranchesType: %s", badgeInfo.Color)
+		}
+	}
+
+	_, err := GenerateBranchesBadgeInfo(OpenPRCountType, BranchesInfo{}, DefaultLocalizer())
+	if err == nil {
+		t.Errorf("Should generate an error")
+	}
+}
+
+func TestRequestStaleBranchAge(t *testing.T) {
+	defer SetStaleBranchPolicy(StaleBranchPolicy{Age: DefaultStaleBranchAge})
+
+	SetStaleBranchPolicy(StaleBranchPolicy{Age: 30 * 24 * time.Hour})
+	if GetStaleBranchPolicy().Age != 30*24*time.Hour {
+		t.Errorf("Set/GetStaleBranchPolicy: Stale branch policies differ")
+	}
+
+	if GetStaleBranchPolicy().requestAge(BadgeRequest{}) != 30*24*time.Hour {
+		t.Errorf("requestAge: Should use the policy age")
+	}
+	if GetStaleBranchPolicy().requestAge(BadgeRequest{Days: 7}) != 7*24*time.Hour {
+		t.Errorf("requestAge: Should use the request number of days")
+	}
+}
@@ -0,0 +1,78 @@
+package bitbadger
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// BuildStatusType shows the build status of a branch, the main branch by
+	// default.
+	BuildStatusType BadgeType = "build-status"
+	// PRBuildPassRateType shows the percentage of open PRs with passing
+	// builds.
+	PRBuildPassRateType BadgeType = "pr-build-pass-rate"
+)
+
+// GenerateBuildBadgeInfo generates a badge from a type and build information.
+func GenerateBuildBadgeInfo(badgeType BadgeType, buildInfo BuildInfo, localizer Localizer) (BadgeInfo, error) {
+	switch badgeType {
+	case BuildStatusType:
+		return generateBuildStatusBadge(buildInfo, localizer), nil
+	case PRBuildPassRateType:
+		return generatePRBuildPassRateBadge(buildInfo, localizer), nil
+	default:
+		return BadgeInfo{}, errors.New("Invalid badge type")
+	}
+}
+
+func generateBuildStatusBadge(buildInfo BuildInfo, localizer Localizer) BadgeInfo {
+	badge := BadgeInfo{
+		Label:   localizer.Text("label.build"),
+		Message: string(buildInfo.State),
+	}
+
+	switch buildInfo.State {
+	case BuildPassing:
+		badge.Color = "brightgreen"
+	case BuildFailing:
+		badge.Color = "red"
+	case BuildInProgress:
+		badge.Color = "yellow"
+	default:
+		badge.Message = string(BuildUnknown)
+		badge.Color = "lightgrey"
+	}
+
+	return badge
+}
+
+func generatePRBuildPassRateBadge(buildInfo BuildInfo, localizer Localizer) (badge BadgeInfo) {
+	badge = BadgeInfo{
+		Label: localizer.Text("label.pr-builds-passing"),
+	}
+
+	if buildInfo.PRsWithBuilds == 0 {
+		badge.Message = localizer.Text("message.not-available")
+		badge.Color = "lightgrey"
+		return
+	}
+
+	passRate := 100 * buildInfo.PRsPassing / buildInfo.PRsWithBuilds
+	badge.Message = fmt.Sprintf("%d%%", passRate)
+
+	switch {
+	case passRate >= 90:
+		badge.Color = "green"
+	case passRate >= 75:
+		badge.Color = "yellowgreen"
+	case passRate >= 50:
+		badge.Color = "yellow"
+	case passRate >= 25:
+		badge.Color = "orange"
+	default:
+		badge.Color = "red"
+	}
+
+	return
+}
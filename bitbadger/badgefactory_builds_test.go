@@ -0,0 +1,49 @@
+package bitbadger
+
+import (
+	"testing"
+)
+
+func TestGenerateBuildBadgeInfo(t *testing.T) {
+	cases := []struct {
+		inType          BadgeType
+		inInfo          BuildInfo
+		expectedLabel   string
+		expectedMessage string
+		expectedColor   string
+	}{
+		{BuildStatusType, BuildInfo{State: BuildPassing},
+			"Build", "passing", "brightgreen"},
+		{BuildStatusType, BuildInfo{State: BuildFailing},
+			"Build", "failing", "red"},
+		{BuildStatusType, BuildInfo{State: BuildInProgress},
+			"Build", "in progress", "yellow"},
+		{BuildStatusType, BuildInfo{},
+			"Build", "unknown", "lightgrey"},
+		{PRBuildPassRateType, BuildInfo{PRsWithBuilds: 4, PRsPassing: 3},
+			"PR builds passing", "75%", "yellowgreen"},
+		{PRBuildPassRateType, BuildInfo{PRsWithBuilds: 3, PRsPassing: 0},
+			"PR builds passing", "0%", "red"},
+		{PRBuildPassRateType, BuildInfo{},
+			"PR builds passing", "n/a", "lightgrey"},
+	}
+
+	for _, c := range cases {
+		badgeInfo, _ := GenerateBuildBadgeInfo(c.inType, c.inInfo, DefaultLocalizer())
+
+		if badgeInfo.Label != c.expectedLabel {
+			t.Errorf("Incorrect label for %s: %s", c.inType, badgeInfo.Label)
+		}
+		if badgeInfo.Message != c.expectedMessage {
+			t.Errorf("Incorrect message for %s: %s", c.inType, badgeInfo.Message)
+		}
+		if badgeInfo.Color != c.expectedColor {
+			t.Errorf("Incorrect color for %s: %s", c.inType, badgeInfo.Color)
+		}
+	}
+
+	_, err := GenerateBuildBadgeInfo(OpenPRCountType, BuildInfo{}, DefaultLocalizer())
+	if err == nil {
+		t.Errorf("Should generate an error")
+	}
+}
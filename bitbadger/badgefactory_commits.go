@@ -0,0 +1,160 @@
+package bitbadger
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+const (
+	// LastCommitType shows the age of the latest commit on a branch, the main
+	// branch by default.
+	LastCommitType BadgeType = "last-commit"
+	// CommitActivityType shows the number of commits per week on a branch,
+	// the main branch by default.
+	CommitActivityType BadgeType = "commit-activity"
+	// ContributorsType shows the number of distinct commit authors on a
+	// branch, the main branch by default.
+	ContributorsType BadgeType = "contributors"
+	// BusFactorType shows the minimum number of authors responsible for half
+	// of the commits on a branch, the main branch by default.
+	BusFactorType BadgeType = "bus-factor"
+)
+
+// ContributorsPolicy holds the settings used to compute contributor metrics.
+type ContributorsPolicy struct {
+	// Window is the rolling window over which commit authors are considered,
+	// unless overridden by the request.
+	Window time.Duration
+}
+
+// DefaultContributorsWindow is the default rolling window used to compute
+// contributor metrics.
+const DefaultContributorsWindow = 90 * 24 * time.Hour
+
+var contributorsPolicy = ContributorsPolicy{
+	Window: DefaultContributorsWindow,
+}
+
+// SetContributorsPolicy sets the global contributors policy.
+func SetContributorsPolicy(policy ContributorsPolicy) {
+	contributorsPolicy = policy
+}
+
+// GetContributorsPolicy returns the current global contributors policy.
+func GetContributorsPolicy() ContributorsPolicy {
+	return contributorsPolicy
+}
+
+// requestWindow returns the contributors window to use for request, which
+// can override the policy window with a number of days.
+func (policy ContributorsPolicy) requestWindow(request BadgeRequest) time.Duration {
+	if request.Days > 0 {
+		return time.Duration(request.Days) * 24 * time.Hour
+	}
+
+	if policy.Window <= 0 {
+		return DefaultContributorsWindow
+	}
+
+	return policy.Window
+}
+
+// GenerateCommitsBadgeInfo generates a badge from a type and commits
+// information.
+func GenerateCommitsBadgeInfo(badgeType BadgeType, commitsInfo CommitsInfo, localizer Localizer) (BadgeInfo, error) {
+	return defaultBadgeFactory().commitsBadgeInfo(badgeType, commitsInfo, localizer)
+}
+
+// commitsBadgeInfo generates a badge from a type and commits information.
+func (factory badgeFactory) commitsBadgeInfo(badgeType BadgeType, commitsInfo CommitsInfo, localizer Localizer) (BadgeInfo, error) {
+	switch badgeType {
+	case LastCommitType:
+		return generateLastCommitBadge(commitsInfo, localizer), nil
+	case CommitActivityType:
+		return factory.generateCommitActivityBadge(commitsInfo, localizer), nil
+	case ContributorsType:
+		return generateContributorsBadge(commitsInfo, localizer), nil
+	case BusFactorType:
+		return generateBusFactorBadge(commitsInfo, localizer), nil
+	default:
+		return BadgeInfo{}, errors.New("Invalid badge type")
+	}
+}
+
+func generateLastCommitBadge(commitsInfo CommitsInfo, localizer Localizer) (badge BadgeInfo) {
+	badge = BadgeInfo{
+		Label:   localizer.Text("label.last-commit"),
+		Message: localizer.Text("message.ago", localizer.Duration(commitsInfo.LastCommitAge)),
+	}
+
+	switch {
+	case commitsInfo.LastCommitAge < 7*24*time.Hour:
+		badge.Color = "green"
+	case commitsInfo.LastCommitAge < 30*24*time.Hour:
+		badge.Color = "yellowgreen"
+	case commitsInfo.LastCommitAge < 90*24*time.Hour:
+		badge.Color = "yellow"
+	case commitsInfo.LastCommitAge < 180*24*time.Hour:
+		badge.Color = "orange"
+	default:
+		badge.Color = "red"
+	}
+
+	return
+}
+
+func (factory badgeFactory) generateCommitActivityBadge(commitsInfo CommitsInfo, localizer Localizer) BadgeInfo {
+	weeklyRate := throughputRate(commitsInfo.CommitCount, commitsInfo.Window, 7*24*time.Hour)
+	return BadgeInfo{
+		Label:   localizer.Text("label.commit-activity"),
+		Message: printRate(weeklyRate, "week", localizer),
+		Color:   rateColor(weeklyRate, factory.thresholds.CommitActivity),
+	}
+}
+
+func generateContributorsBadge(commitsInfo CommitsInfo, localizer Localizer) (badge BadgeInfo) {
+	badge = BadgeInfo{
+		Label:   localizer.Text("label.contributors"),
+		Message: strconv.Itoa(commitsInfo.Contributors),
+	}
+
+	switch {
+	case commitsInfo.Contributors >= 5:
+		badge.Color = "green"
+	case commitsInfo.Contributors >= 3:
+		badge.Color = "yellowgreen"
+	case commitsInfo.Contributors == 2:
+		badge.Color = "yellow"
+	case commitsInfo.Contributors == 1:
+		badge.Color = "orange"
+	default:
+		badge.Color = "red"
+	}
+
+	return
+}
+
+func generateBusFactorBadge(commitsInfo CommitsInfo, localizer Localizer) (badge BadgeInfo) {
+	badge = BadgeInfo{
+		Label:   localizer.Text("label.bus-factor"),
+		Message: strconv.Itoa(commitsInfo.BusFactor),
+	}
+
+	switch {
+	case commitsInfo.BusFactor >= 4:
+		badge.Color = "green"
+	case commitsInfo.BusFactor == 3:
+		badge.Color = "yellowgreen"
+	case commitsInfo.BusFactor == 2:
+		badge.Color = "yellow"
+	case commitsInfo.BusFactor == 1:
+		badge.Color = "red"
+	default:
+		// No recent commit, the bus factor is meaningless.
+		badge.Message = localizer.Text("message.not-available")
+		badge.Color = "lightgrey"
+	}
+
+	return
+}
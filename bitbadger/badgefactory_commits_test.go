@@ -0,0 +1,85 @@
+package bitbadger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGenerateCommitsBadgeInfo(t *testing.T) {
+	cases := []struct {
+		inType          BadgeType
+		inInfo          CommitsInfo
+		expectedLabel   string
+		expectedMessage string
+		expectedColor   string
+	}{
+		{LastCommitType, CommitsInfo{LastCommitAge: 3 * time.Hour},
+			"Last commit", "3 hours ago", "green"},
+		{LastCommitType, CommitsInfo{LastCommitAge: 200 * 24 * time.Hour},
+			"Last commit", "200 days ago", "red"},
+		{CommitActivityType, CommitsInfo{CommitCount: 30, Window: 28 * 24 * time.Hour},
+			"Commit activity", "7.5/week", "yellowgreen"},
+		{CommitActivityType, CommitsInfo{Window: 28 * 24 * time.Hour},
+			"Commit activity", "0/week", "red"},
+		{ContributorsType, CommitsInfo{Contributors: 3},
+			"Contributors", "3", "yellowgreen"},
+		{BusFactorType, CommitsInfo{BusFactor: 1},
+			"Bus factor", "1", "red"},
+		{BusFactorType, CommitsInfo{},
+			"Bus factor", "n/a", "lightgrey"},
+	}
+
+	for _, c := range cases {
+		badgeInfo, _ := GenerateCommitsBadgeInfo(c.inType, c.inInfo, DefaultLocalizer())
+
+		if badgeInfo.Label != c.expectedLabel {
+			t.Errorf("Incorrect label for %s: %s", c.inType, badgeInfo.Label)
+		}
+		if badgeInfo.Message != c.expectedMessage {
+			t.Errorf("Incorrect message for %s: %s", c.inType, badgeInfo.Message)
+		}
+		if badgeInfo.Color != c.expectedColor {
+			t.Errorf("Incorrect color for %s: %s", c.inType, badgeInfo.Color)
+		}
+	}
+
+	_, err := GenerateCommitsBadgeInfo(OpenPRCountType, CommitsInfo{}, DefaultLocalizer())
+	if err == nil {
+		t.Errorf("Should generate an error")
+	}
+}
+
+func TestRequestContributorsWindow(t *testing.T) {
+	defer SetContributorsPolicy(ContributorsPolicy{Window: DefaultContributorsWindow})
+
+	SetContributorsPolicy(ContributorsPolicy{Window: 30 * 24 * time.Hour})
+	if GetContributorsPolicy().Window != 30*24*time.Hour {
+		t.Errorf("Set/GetContributorsPolicy: Contributors policies differ")
+	}
+
+	if GetContributorsPolicy().requestWindow(BadgeRequest{}) != 30*24*time.Hour {
+		t.Errorf("requestWindow: Should use the policy window")
+	}
+	if GetContributorsPolicy().requestWindow(BadgeRequest{Days: 7}) != 7*24*time.Hour {
+		t.Errorf("requestWindow: Should use the request number of days")
+	}
+}
+
+func TestCommitsBadgeURL(t *testing.T) {
+	cases := []struct {
+		inType          BadgeType
+		inInfo          CommitsInfo
+		expectedMessage string
+	}{
+		{CommitActivityType, CommitsInfo{CommitCount: 12, Window: 28 * 24 * time.Hour}, "3/week"},
+		{BusFactorType, CommitsInfo{}, "n/a"},
+	}
+
+	for _, c := range cases {
+		badgeInfo, _ := GenerateCommitsBadgeInfo(c.inType, c.inInfo, DefaultLocalizer())
+		_, message, _ := parseBadgeURL(t, generateBadgeURL(badgeInfo))
+		if message != c.expectedMessage {
+			t.Errorf("generateBadgeURL: Expected message '%s' for %s, got '%s'", c.expectedMessage, c.inType, message)
+		}
+	}
+}
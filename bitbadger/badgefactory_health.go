@@ -0,0 +1,49 @@
+package bitbadger
+
+import (
+	"errors"
+	"strconv"
+)
+
+const (
+	// HealthType shows a score combining several pull request metrics, see
+	// HealthPolicy.
+	HealthType BadgeType = "health"
+)
+
+// GenerateHealthBadgeInfo generates a badge from a type and health
+// information.
+func GenerateHealthBadgeInfo(badgeType BadgeType, healthInfo HealthInfo, localizer Localizer) (BadgeInfo, error) {
+	switch badgeType {
+	case HealthType:
+		return generateHealthBadge(healthInfo, localizer), nil
+	default:
+		return BadgeInfo{}, errors.New("Invalid badge type")
+	}
+}
+
+func generateHealthBadge(healthInfo HealthInfo, localizer Localizer) (badge BadgeInfo) {
+	badge = BadgeInfo{
+		Label:   localizer.Text("label.health"),
+		Message: strconv.FormatFloat(healthInfo.Score, 'f', 0, 64) + "/100",
+	}
+
+	if healthInfo.UseGrade {
+		badge.Message = healthInfo.Grade
+	}
+
+	switch healthInfo.Grade {
+	case "A":
+		badge.Color = "green"
+	case "B":
+		badge.Color = "yellowgreen"
+	case "C":
+		badge.Color = "yellow"
+	case "D":
+		badge.Color = "orange"
+	default:
+		badge.Color = "red"
+	}
+
+	return
+}
@@ -0,0 +1,39 @@
+package bitbadger
+
+import (
+	"testing"
+)
+
+func TestGenerateHealthBadgeInfo(t *testing.T) {
+	cases := []struct {
+		inInfo          HealthInfo
+		expectedMessage string
+		expectedColor   string
+	}{
+		{HealthInfo{Score: 95, Grade: "A"}, "95/100", "green"},
+		{HealthInfo{Score: 72, Grade: "C", UseGrade: true}, "C", "yellow"},
+		{HealthInfo{Score: 10, Grade: "F"}, "10/100", "red"},
+	}
+
+	for _, c := range cases {
+		badgeInfo, _ := GenerateHealthBadgeInfo(HealthType, c.inInfo, DefaultLocalizer())
+
+		if badgeInfo.Label != "Health" {
+			t.Errorf("Incorrect label for HealthType: %s", badgeInfo.Label)
+		}
+		if badgeInfo.Message != c.expectedMessage {
+			t.Errorf("Incorrect message for HealthType: %s", badgeInfo.Message)
+		}
+		if badgeInfo.Color != c.expectedColor {
+			t.Errorf("Incorrect color for HealthType: %s", badgeInfo.Color)
+		}
+		if _, message, _ := parseBadgeURL(t, generateBadgeURL(badgeInfo)); message != c.expectedMessage {
+			t.Errorf("Incorrect URL message for HealthType: %s", message)
+		}
+	}
+
+	_, err := GenerateHealthBadgeInfo(OpenPRCountType, HealthInfo{}, DefaultLocalizer())
+	if err == nil {
+		t.Errorf("Should generate an error")
+	}
+}
@@ -0,0 +1,59 @@
+package bitbadger
+
+import (
+	"errors"
+	"strconv"
+)
+
+const (
+	// OpenIssueCountType shows the number of open issues.
+	OpenIssueCountType BadgeType = "open-issue-count"
+	// OldestOpenIssueAgeType shows the age of the oldest open issue.
+	OldestOpenIssueAgeType BadgeType = "oldest-open-issue-age"
+)
+
+// IssueKinds lists the valid issue kinds to filter issues with.
+var IssueKinds = []string{"bug", "enhancement", "proposal", "task"}
+
+// IssuePriorities lists the valid issue priorities to filter issues with.
+var IssuePriorities = []string{"trivial", "minor", "major", "critical", "blocker"}
+
+// GenerateIssuesBadgeInfo generates a badge from a type and issues
+// information.
+func GenerateIssuesBadgeInfo(badgeType BadgeType, issuesInfo IssuesInfo, localizer Localizer) (BadgeInfo, error) {
+	return defaultBadgeFactory().issuesBadgeInfo(badgeType, issuesInfo, localizer)
+}
+
+// issuesBadgeInfo generates a badge from a type and issues information.
+func (factory badgeFactory) issuesBadgeInfo(badgeType BadgeType, issuesInfo IssuesInfo, localizer Localizer) (BadgeInfo, error) {
+	switch badgeType {
+	case OpenIssueCountType:
+		return BadgeInfo{
+			Label:   issuesLabel(localizer.Text("label.open-issues"), issuesInfo),
+			Message: strconv.Itoa(issuesInfo.OpenCount),
+			Color:   factory.openCountColor(issuesInfo.OpenCount),
+		}, nil
+	case OldestOpenIssueAgeType:
+		return BadgeInfo{
+			Label:   issuesLabel(localizer.Text("label.oldest-issue-age"), issuesInfo),
+			Message: localizer.Duration(issuesInfo.OldestOpenIssue),
+			Color:   factory.openTimeColor(issuesInfo.OldestOpenIssue),
+		}, nil
+	default:
+		return BadgeInfo{}, errors.New("Invalid badge type")
+	}
+}
+
+// issuesLabel appends the kind and priority filters to label, if any.
+func issuesLabel(label string, issuesInfo IssuesInfo) string {
+	switch {
+	case issuesInfo.Kind != "" && issuesInfo.Priority != "":
+		return label + " (" + issuesInfo.Priority + " " + issuesInfo.Kind + ")"
+	case issuesInfo.Kind != "":
+		return label + " (" + issuesInfo.Kind + ")"
+	case issuesInfo.Priority != "":
+		return label + " (" + issuesInfo.Priority + ")"
+	default:
+		return label
+	}
+}
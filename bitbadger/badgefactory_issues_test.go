@@ -0,0 +1,44 @@
+package bitbadger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGenerateIssuesBadgeInfo(t *testing.T) {
+	cases := []struct {
+		inType          BadgeType
+		inInfo          IssuesInfo
+		expectedLabel   string
+		expectedMessage string
+		expectedColor   string
+	}{
+		{OpenIssueCountType, IssuesInfo{OpenCount: 4},
+			"Open issues", "4", "yellowgreen"},
+		{OpenIssueCountType, IssuesInfo{Kind: "bug", OpenCount: 12},
+			"Open issues (bug)", "12", "red"},
+		{OldestOpenIssueAgeType, IssuesInfo{Priority: "critical", OldestOpenIssue: 5 * time.Hour},
+			"Oldest issue age (critical)", "5 hours", "green"},
+		{OldestOpenIssueAgeType, IssuesInfo{Kind: "bug", Priority: "major", OldestOpenIssue: 50 * time.Hour},
+			"Oldest issue age (major bug)", "2 days 2 hours", "yellow"},
+	}
+
+	for _, c := range cases {
+		badgeInfo, _ := GenerateIssuesBadgeInfo(c.inType, c.inInfo, DefaultLocalizer())
+
+		if badgeInfo.Label != c.expectedLabel {
+			t.Errorf("Incorrect label for %s: %s", c.inType, badgeInfo.Label)
+		}
+		if badgeInfo.Message != c.expectedMessage {
+			t.Errorf("Incorrect message for %s: %s", c.inType, badgeInfo.Message)
+		}
+		if badgeInfo.Color != c.expectedColor {
+			t.Errorf("Incorrect color for %s: %s", c.inType, badgeInfo.Color)
+		}
+	}
+
+	_, err := GenerateIssuesBadgeInfo(OpenPRCountType, IssuesInfo{}, DefaultLocalizer())
+	if err == nil {
+		t.Errorf("Should generate an error")
+	}
+}
@@ -0,0 +1,51 @@
+package bitbadger
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	// SummaryType shows several metrics in a single badge, with one segment
+	// for each metric.
+	SummaryType BadgeType = "summary"
+)
+
+// colorSeverities orders badge colors from the best to the worst.
+var colorSeverities = []string{
+	"lightgrey", "brightgreen", "green", "yellowgreen", "yellow", "orange", "red",
+}
+
+// GenerateSummaryBadgeInfo generates a badge from a type and summary
+// information. All the parts are joined in the message, and the worst color
+// is used.
+func GenerateSummaryBadgeInfo(badgeType BadgeType, summaryInfo SummaryInfo) (BadgeInfo, error) {
+	if badgeType != SummaryType {
+		return BadgeInfo{}, errors.New("Invalid badge type")
+	}
+
+	messages := []string{}
+	color := colorSeverities[0]
+	for _, part := range summaryInfo.Parts {
+		messages = append(messages, part.Badge.Message)
+		if colorSeverity(part.Badge.Color) > colorSeverity(color) {
+			color = part.Badge.Color
+		}
+	}
+
+	return BadgeInfo{
+		Label:   summaryInfo.Label,
+		Message: strings.Join(messages, " | "),
+		Color:   color,
+	}, nil
+}
+
+func colorSeverity(color string) int {
+	for severity, severityColor := range colorSeverities {
+		if color == severityColor {
+			return severity
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,57 @@
+package bitbadger
+
+import (
+	"testing"
+)
+
+func TestGenerateSummaryBadgeInfo(t *testing.T) {
+	summaryInfo := SummaryInfo{
+		Label: "PRs",
+		Parts: []MetricsReport{
+			{Badge: summaryPartBadge(OpenPRCountType, BadgeInfo{Label: "Open PRs", Message: "4", Color: "yellowgreen"}, DefaultLocalizer())},
+			{Badge: summaryPartBadge(OldestOpenPRAge, BadgeInfo{Label: "Oldest PR age", Message: "3 days", Color: "orange"}, DefaultLocalizer())},
+			{Badge: summaryPartBadge(BuildStatusType, BadgeInfo{Label: "Build", Message: "passing", Color: "brightgreen"}, DefaultLocalizer())},
+		},
+	}
+
+	badgeInfo, err := GenerateSummaryBadgeInfo(SummaryType, summaryInfo)
+	if err != nil {
+		t.Errorf("GenerateSummaryBadgeInfo: Unexpected error: %s", err)
+	}
+	if badgeInfo.Label != "PRs" {
+		t.Errorf("Incorrect label for SummaryType: %s", badgeInfo.Label)
+	}
+	if badgeInfo.Message != "open 4 | oldest 3 days | build passing" {
+		t.Errorf("Incorrect message for SummaryType: %s", badgeInfo.Message)
+	}
+	if badgeInfo.Color != "orange" {
+		t.Errorf("Incorrect color for SummaryType: %s", badgeInfo.Color)
+	}
+
+	segments := summaryInfo.Segments()
+	if len(segments) != 4 || segments[0].Text != "PRs" || segments[2].Color != "orange" {
+		t.Errorf("Segments: Unexpected segments %+v", segments)
+	}
+
+	_, err = GenerateSummaryBadgeInfo(OpenPRCountType, summaryInfo)
+	if err == nil {
+		t.Errorf("Should generate an error")
+	}
+}
+
+func TestParseSummaryParts(t *testing.T) {
+	parts, err := parseSummaryParts("")
+	if err != nil || len(parts) != len(DefaultSummaryParts) {
+		t.Errorf("parseSummaryParts: Should default to DefaultSummaryParts")
+	}
+
+	parts, err = parseSummaryParts("open-pr-count, build-status")
+	if err != nil || len(parts) != 2 || parts[0] != OpenPRCountType || parts[1] != BuildStatusType {
+		t.Errorf("parseSummaryParts: Unexpected parts %v", parts)
+	}
+
+	_, err = parseSummaryParts("open-pr-count,summary")
+	if err == nil {
+		t.Errorf("parseSummaryParts: Should generate an error for nested summaries")
+	}
+}
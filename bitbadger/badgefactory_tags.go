@@ -0,0 +1,47 @@
+package bitbadger
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// LatestTagType shows the tag with the highest semantic version.
+	LatestTagType BadgeType = "latest-tag"
+)
+
+// GenerateTagsBadgeInfo generates a badge from a type and tags information.
+func GenerateTagsBadgeInfo(badgeType BadgeType, tagsInfo TagsInfo, localizer Localizer) (BadgeInfo, error) {
+	switch badgeType {
+	case LatestTagType:
+		return generateLatestTagBadge(tagsInfo, localizer), nil
+	default:
+		return BadgeInfo{}, errors.New("Invalid badge type")
+	}
+}
+
+func generateLatestTagBadge(tagsInfo TagsInfo, localizer Localizer) (badge BadgeInfo) {
+	badge = BadgeInfo{
+		Label:   localizer.Text("label.latest-tag"),
+		Message: tagsInfo.LatestTag,
+	}
+
+	// Colors reflect how long ago the latest release was made.
+	switch {
+	case tagsInfo.LatestTag == "":
+		badge.Message = localizer.Text("message.none")
+		badge.Color = "lightgrey"
+	case tagsInfo.LatestTagAge < 30*24*time.Hour:
+		badge.Color = "green"
+	case tagsInfo.LatestTagAge < 90*24*time.Hour:
+		badge.Color = "yellowgreen"
+	case tagsInfo.LatestTagAge < 180*24*time.Hour:
+		badge.Color = "yellow"
+	case tagsInfo.LatestTagAge < 365*24*time.Hour:
+		badge.Color = "orange"
+	default:
+		badge.Color = "red"
+	}
+
+	return
+}
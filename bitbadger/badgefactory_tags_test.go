@@ -0,0 +1,48 @@
+package bitbadger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGenerateTagsBadgeInfo(t *testing.T) {
+	cases := []struct {
+		inInfo          TagsInfo
+		expectedMessage string
+		expectedColor   string
+	}{
+		{TagsInfo{LatestTag: "v1.2.0", LatestTagAge: 2 * 24 * time.Hour}, "v1.2.0", "green"},
+		{TagsInfo{LatestTag: "v1.2.0", LatestTagAge: 100 * 24 * time.Hour}, "v1.2.0", "yellow"},
+		{TagsInfo{LatestTag: "v0.1.0", LatestTagAge: 400 * 24 * time.Hour}, "v0.1.0", "red"},
+		{TagsInfo{}, "none", "lightgrey"},
+	}
+
+	for _, c := range cases {
+		badgeInfo, _ := GenerateTagsBadgeInfo(LatestTagType, c.inInfo, DefaultLocalizer())
+
+		if badgeInfo.Label != "Latest tag" {
+			t.Errorf("Incorrect label for LatestTagType: %s", badgeInfo.Label)
+		}
+		if badgeInfo.Message != c.expectedMessage {
+			t.Errorf("Incorrect message for LatestTagType: %s", badgeInfo.Message)
+		}
+		if badgeInfo.Color != c.expectedColor {
+			t.Errorf("Incorrect color for LatestTagType: %s", badgeInfo.Color)
+		}
+	}
+
+	_, err := GenerateTagsBadgeInfo(OpenPRCountType, TagsInfo{}, DefaultLocalizer())
+	if err == nil {
+		t.Errorf("Should generate an error")
+	}
+}
+
+func TestTagsBadgeURL(t *testing.T) {
+	for _, tag := range []string{"v2.0.0-rc.1", "api-3.0.0", "release_2020/01"} {
+		badgeInfo, _ := GenerateTagsBadgeInfo(LatestTagType, TagsInfo{LatestTag: tag, LatestTagAge: time.Hour}, DefaultLocalizer())
+		_, message, _ := parseBadgeURL(t, generateBadgeURL(badgeInfo))
+		if message != tag {
+			t.Errorf("generateBadgeURL: Expected message '%s', got '%s'", tag, message)
+		}
+	}
+}
@@ -14,6 +14,25 @@ func TestGetBadgeType(t *testing.T) {
 		{string(OpenPRAverageAgeType), OpenPRAverageAgeType},
 		{string(OldestOpenPRAge), OldestOpenPRAge},
 		{string(AveragePRMergeTime), AveragePRMergeTime},
+		{string(DraftPRCountType), DraftPRCountType},
+		{string(AwaitingReviewCountType), AwaitingReviewCountType},
+		{string(AverageReviewersType), AverageReviewersType},
+		{string(PendingReviewsType), PendingReviewsType},
+		{string(MergedPRsPerDayType), MergedPRsPerDayType},
+		{string(MergedPRsPerWeekType), MergedPRsPerWeekType},
+		{string(MergedPRsPerMonthType), MergedPRsPerMonthType},
+		{string(BuildStatusType), BuildStatusType},
+		{string(PRBuildPassRateType), PRBuildPassRateType},
+		{string(LastCommitType), LastCommitType},
+		{string(CommitActivityType), CommitActivityType},
+		{string(ContributorsType), ContributorsType},
+		{string(BusFactorType), BusFactorType},
+		{string(StaleBranchesType), StaleBranchesType},
+		{string(OpenIssueCountType), OpenIssueCountType},
+		{string(OldestOpenIssueAgeType), OldestOpenIssueAgeType},
+		{string(LatestTagType), LatestTagType},
+		{string(HealthType), HealthType},
+		{string(SummaryType), SummaryType},
 	}
 
 	for _, c := range cases {
@@ -51,6 +70,25 @@ func TestGenerateBadgeInfo(t *testing.T) {
 		{AveragePRMergeTime, PullRequestsInfo{
 			AveragePRMergeTime: 5 * time.Minute},
 			"Avg. PR merge time", "5 mins", "green"},
+		{DraftPRCountType, PullRequestsInfo{DraftCount: 3},
+			"Draft PRs", "3", "lightgrey"},
+		{AwaitingReviewCountType, PullRequestsInfo{AwaitingReviewCount: 4},
+			"PRs awaiting review", "4", "yellow"},
+		{AverageReviewersType, PullRequestsInfo{AverageReviewers: 1.75},
+			"Avg. reviewers per PR", "1.8", "yellowgreen"},
+		{AverageReviewersType, PullRequestsInfo{},
+			"Avg. reviewers per PR", "0.0", "red"},
+		{PendingReviewsType, PullRequestsInfo{PendingReviewCount: 0},
+			"Pending reviews", "0", "green"},
+		{MergedPRsPerWeekType, PullRequestsInfo{
+			MergedCount: 12, MergedWindow: 28 * 24 * time.Hour},
+			"Merged PRs", "3/week", "yellow"},
+		{MergedPRsPerDayType, PullRequestsInfo{
+			MergedCount: 12, MergedWindow: 28 * 24 * time.Hour},
+			"Merged PRs", "0.4/day", "yellow"},
+		{MergedPRsPerMonthType, PullRequestsInfo{
+			MergedCount: 12, MergedWindow: 28 * 24 * time.Hour},
+			"Merged PRs", "13/month", "yellow"},
 	}
 
 	for _, c := range cases {
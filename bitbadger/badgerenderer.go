@@ -0,0 +1,118 @@
+package bitbadger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// BadgeSegment holds the text and color of a segment of a rendered badge.
+type BadgeSegment struct {
+	Text  string
+	Color string
+}
+
+// Renderer renders badge images from their segments. Badges have two
+// segments, the label and the message, except summaries which have more.
+type Renderer interface {
+	Render(ctx context.Context, segments []BadgeSegment) (*BadgeImage, error)
+}
+
+// LocalRenderer renders badges without any external service.
+type LocalRenderer struct{}
+
+// Render renders a badge from its segments.
+func (LocalRenderer) Render(ctx context.Context, segments []BadgeSegment) (*BadgeImage, error) {
+	return RenderSegmentedBadge(segments), nil
+}
+
+// labelColor is the color of badge labels.
+const labelColor = "grey"
+
+// namedColors maps the color names used by badges to their shields.io values.
+var namedColors = map[string]string{
+	"brightgreen": "#4c1",
+	"green":       "#97ca00",
+	"yellowgreen": "#a4a61d",
+	"yellow":      "#dfb317",
+	"orange":      "#fe7d37",
+	"red":         "#e05d44",
+	"blue":        "#007ec6",
+	"lightgrey":   "#9f9f9f",
+	"grey":        "#555",
+}
+
+const (
+	badgeHeight         = 20
+	badgeSegmentPadding = 6
+)
+
+// RenderSegmentedBadge renders a badge with any number of segments, in the
+// shields.io "flat" style.
+func RenderSegmentedBadge(segments []BadgeSegment) *BadgeImage {
+	var shapes, texts bytes.Buffer
+	width := 0
+	for _, segment := range segments {
+		textWidth := estimateTextWidth(segment.Text)
+		segmentWidth := textWidth + 2*badgeSegmentPadding
+
+		fmt.Fprintf(&shapes, `<rect x="%d" width="%d" height="%d" fill="%s"/>`,
+			width, segmentWidth, badgeHeight, segmentColor(segment.Color))
+
+		text := html.EscapeString(segment.Text)
+		textX := width + segmentWidth/2
+		fmt.Fprintf(&texts, `<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text>`, textX, text)
+		fmt.Fprintf(&texts, `<text x="%d" y="14">%s</text>`, textX, text)
+
+		width += segmentWidth
+	}
+
+	var svg bytes.Buffer
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d">`, width, badgeHeight)
+	svg.WriteString(`<linearGradient id="s" x2="0" y2="100%">`)
+	svg.WriteString(`<stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/>`)
+	svg.WriteString(`</linearGradient>`)
+	fmt.Fprintf(&svg, `<clipPath id="r"><rect width="%d" height="%d" rx="3" fill="#fff"/></clipPath>`, width, badgeHeight)
+	svg.WriteString(`<g clip-path="url(#r)">`)
+	svg.Write(shapes.Bytes())
+	fmt.Fprintf(&svg, `<rect width="%d" height="%d" fill="url(#s)"/>`, width, badgeHeight)
+	svg.WriteString(`</g>`)
+	svg.WriteString(`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`)
+	svg.Write(texts.Bytes())
+	svg.WriteString(`</g></svg>`)
+
+	return &BadgeImage{
+		Data:      svg.Bytes(),
+		Extension: "svg+xml",
+	}
+}
+
+func segmentColor(color string) string {
+	if hexColor, found := namedColors[color]; found {
+		return hexColor
+	}
+
+	return namedColors["lightgrey"]
+}
+
+// estimateTextWidth estimates the width in pixels of text rendered with an
+// 11px Verdana font, which is what badges use.
+func estimateTextWidth(text string) int {
+	width := float64(0)
+	for _, char := range text {
+		switch {
+		case strings.ContainsRune("iljtfr.,:;|!' ", char):
+			width += 4
+		case strings.ContainsRune("mwMW", char):
+			width += 10
+		case char >= 'A' && char <= 'Z':
+			width += 7.5
+		default:
+			width += 7
+		}
+	}
+
+	return int(width + 0.5)
+}
@@ -0,0 +1,54 @@
+package bitbadger
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestRenderSegmentedBadge(t *testing.T) {
+	image := RenderSegmentedBadge([]BadgeSegment{
+		{Text: "PRs", Color: labelColor},
+		{Text: "open 4", Color: "yellowgreen"},
+		{Text: "<oldest> & co", Color: "unknown"},
+	})
+
+	if image.Extension != "svg+xml" {
+		t.Errorf("RenderSegmentedBadge: Invalid extension %s", image.Extension)
+	}
+
+	var svg struct{}
+	if err := xml.Unmarshal(image.Data, &svg); err != nil {
+		t.Errorf("RenderSegmentedBadge: Invalid SVG: %s", err)
+	}
+
+	data := string(image.Data)
+	for _, expected := range []string{"PRs", "open 4", "&lt;oldest&gt; &amp; co", `fill="#555"`, `fill="#a4a61d"`, `fill="#9f9f9f"`} {
+		if !strings.Contains(data, expected) {
+			t.Errorf("RenderSegmentedBadge: Badge should contain '%s'", expected)
+		}
+	}
+}
+
+func TestEstimateTextWidth(t *testing.T) {
+	if estimateTextWidth("") != 0 {
+		t.Errorf("estimateTextWidth: Empty text should have no width")
+	}
+	if estimateTextWidth("iii") >= estimateTextWidth("mmm") {
+		t.Errorf("estimateTextWidth: Narrow characters should be narrower than wide ones")
+	}
+}
+
+func TestLocalRenderer(t *testing.T) {
+	badge := BadgeInfo{Label: "Open PRs", Message: "3", Color: "green"}
+	image, err := LocalRenderer{}.Render(context.Background(), badge.Segments())
+	if err != nil {
+		t.Fatalf("Render: Unexpected error: %s", err)
+	}
+
+	if !bytes.Equal(image.Data, RenderSegmentedBadge(badge.Segments()).Data) || image.Extension != "svg+xml" {
+		t.Errorf("Render: Should render badges locally")
+	}
+}
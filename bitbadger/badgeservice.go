@@ -0,0 +1,101 @@
+package bitbadger
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// shieldsEscaper escapes the dashes and underscores of badge texts, which
+// are separators in shields.io format.
+var shieldsEscaper = strings.NewReplacer("-", "--", "_", "__")
+
+// escapeBadgePart escapes text to be used as a part of a shields.io badge
+// URL. Characters such as '/' or '%' are percent-encoded, and spaces are
+// encoded as "%20".
+func escapeBadgePart(text string) string {
+	return url.PathEscape(shieldsEscaper.Replace(text))
+}
+
+func generateBadgeURL(badge BadgeInfo) string {
+	// Label, message and color are '-' separate in shields.io format.
+	return fmt.Sprintf("https://img.shields.io/badge/%s-%s-%s",
+		escapeBadgePart(badge.Label), escapeBadgePart(badge.Message), escapeBadgePart(badge.Color))
+}
+
+// ShieldsRenderer renders badges with "img.shields.io". Badges with more than
+// two segments, which shields.io doesn't support, are rendered locally.
+type ShieldsRenderer struct {
+	// Client sends requests to shields.io. A nil client uses
+	// http.DefaultClient.
+	Client *http.Client
+	// Timeout is the maximum duration of requests to shields.io. Zero means
+	// no timeout.
+	Timeout time.Duration
+}
+
+// Render renders a badge from its segments.
+func (renderer ShieldsRenderer) Render(ctx context.Context, segments []BadgeSegment) (*BadgeImage, error) {
+	if len(segments) != 2 {
+		return LocalRenderer{}.Render(ctx, segments)
+	}
+
+	return renderer.download(ctx, BadgeInfo{
+		Label:   segments[0].Text,
+		Message: segments[1].Text,
+		Color:   segments[1].Color,
+	})
+}
+
+// DownloadBadge downloads and returns a badge image from "img.shields.io",
+// using badgeInfo.
+func DownloadBadge(badgeInfo BadgeInfo) (*BadgeImage, error) {
+	return ShieldsRenderer{}.download(context.Background(), badgeInfo)
+}
+
+func (renderer ShieldsRenderer) download(ctx context.Context, badgeInfo BadgeInfo) (*BadgeImage, error) {
+	client := renderer.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	if renderer.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, renderer.Timeout)
+		defer cancel()
+	}
+
+	// Get the data
+	badgeURL := generateBadgeURL(badgeInfo)
+	log.Debug("Badge URL = ", badgeURL)
+
+	req, err := http.NewRequest("GET", badgeURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		log.Error("Error while retrieving badge at '", badgeURL, "': ", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		log.Error("Failed to read badge image response: ", err)
+		return nil, err
+	}
+
+	image := &BadgeImage{
+		Data:      body,
+		Extension: "svg+xml",
+	}
+	return image, nil
+}
@@ -0,0 +1,79 @@
+package bitbadger
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestBadgeURL(t *testing.T) {
+	badgeURL := generateBadgeURL(BadgeInfo{
+		Label:   "My label",
+		Message: "My message",
+		Color:   "somecolor",
+	})
+	if badgeURL != "https://img.shields.io/badge/My%20label-My%20message-somecolor" {
+		t.Errorf("generateBadgeURL: Invalid badge URL generated %s", badgeURL)
+	}
+}
+
+// parseBadgeURL splits a shields.io badge URL into its label, message and
+// color, as shields.io does.
+func parseBadgeURL(t *testing.T, badgeURL string) (string, string, string) {
+	parsed, err := url.Parse(badgeURL)
+	if err != nil {
+		t.Fatalf("generateBadgeURL: Invalid badge URL %s: %s", badgeURL, err)
+	}
+
+	// The escaped path must be a single segment, split by single dashes.
+	escapedPath := strings.TrimPrefix(parsed.EscapedPath(), "/badge/")
+	if strings.Contains(escapedPath, "/") {
+		t.Fatalf("generateBadgeURL: Unescaped '/' in %s", badgeURL)
+	}
+
+	var parts []string
+	var part strings.Builder
+	for i := 0; i < len(escapedPath); i++ {
+		c := escapedPath[i]
+		if (c == '-' || c == '_') && i+1 < len(escapedPath) && escapedPath[i+1] == c {
+			part.WriteByte(c)
+			i++
+			continue
+		}
+		if c == '-' {
+			parts = append(parts, part.String())
+			part.Reset()
+			continue
+		}
+		part.WriteByte(c)
+	}
+	parts = append(parts, part.String())
+	if len(parts) != 3 {
+		t.Fatalf("generateBadgeURL: Expected 3 parts in %s, got %q", badgeURL, parts)
+	}
+
+	for i := range parts {
+		parts[i], err = url.PathUnescape(parts[i])
+		if err != nil {
+			t.Fatalf("generateBadgeURL: Invalid escaping in %s: %s", badgeURL, err)
+		}
+	}
+	return parts[0], parts[1], parts[2]
+}
+
+func TestBadgeURLEscaping(t *testing.T) {
+	cases := []BadgeInfo{
+		{Label: "coverage", Message: "75%", Color: "green"},
+		{Label: "commit activity", Message: "3/week", Color: "blue"},
+		{Label: "bus factor", Message: "n/a", Color: "lightgrey"},
+		{Label: "latest tag", Message: "v2.0.0-rc.1", Color: "blue"},
+		{Label: "my_label", Message: "api-3.0.0_final", Color: "brightgreen"},
+	}
+
+	for _, badge := range cases {
+		label, message, color := parseBadgeURL(t, generateBadgeURL(badge))
+		if label != badge.Label || message != badge.Message || color != badge.Color {
+			t.Errorf("generateBadgeURL: Expected %+v to round-trip, got '%s', '%s', '%s'", badge, label, message, color)
+		}
+	}
+}
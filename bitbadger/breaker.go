@@ -0,0 +1,258 @@
+package bitbadger
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// CircuitBreakerPolicy holds when the circuit breaker of a provider opens, and
+// for how long.
+type CircuitBreakerPolicy struct {
+	// FailureThreshold is the number of consecutive failures opening the
+	// circuit. Zero disables the circuit breaker.
+	FailureThreshold int
+	// OpenDuration is how long the circuit stays open, before a request is
+	// let through to probe the provider.
+	OpenDuration time.Duration
+}
+
+// DefaultCircuitBreakerPolicy is the circuit breaker policy used unless
+// configured otherwise.
+var DefaultCircuitBreakerPolicy = CircuitBreakerPolicy{
+	FailureThreshold: 5,
+	OpenDuration:     30 * time.Second,
+}
+
+var circuitBreakerPolicy = DefaultCircuitBreakerPolicy
+
+// SetCircuitBreakerPolicy sets the global circuit breaker policy, used by the
+// package level functions. Servers use the policy of WithCircuitBreakerPolicy.
+func SetCircuitBreakerPolicy(policy CircuitBreakerPolicy) {
+	circuitBreakerPolicy = policy
+}
+
+// GetCircuitBreakerPolicy returns the current global circuit breaker policy.
+func GetCircuitBreakerPolicy() CircuitBreakerPolicy {
+	return circuitBreakerPolicy
+}
+
+// ErrCircuitOpen is returned instead of querying a provider whose circuit is
+// open.
+var ErrCircuitOpen = errors.New("Upstream server unavailable")
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+// States of a circuit breaker.
+const (
+	// CircuitClosed lets all requests through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects all requests.
+	CircuitOpen
+	// CircuitHalfOpen lets a single request through, to probe the provider.
+	CircuitHalfOpen
+)
+
+func (state CircuitState) String() string {
+	switch state {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreaker stops sending requests to a provider after consecutive
+// failures. It is safe for concurrent use.
+type CircuitBreaker struct {
+	mutex    sync.Mutex
+	name     string
+	policy   CircuitBreakerPolicy
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+	now      func() time.Time
+}
+
+// NewCircuitBreaker returns a closed circuit breaker, identified by name in
+// the server status.
+func NewCircuitBreaker(name string, policy CircuitBreakerPolicy) *CircuitBreaker {
+	return &CircuitBreaker{
+		name:   name,
+		policy: policy,
+		now:    time.Now,
+	}
+}
+
+// allow returns true if a request can be sent to the provider. In half-open
+// state, a single probe request is allowed at a time.
+func (breaker *CircuitBreaker) allow() bool {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	if breaker.policy.FailureThreshold <= 0 {
+		return true
+	}
+
+	switch breaker.state {
+	case CircuitOpen:
+		if breaker.now().Sub(breaker.openedAt) < breaker.policy.OpenDuration {
+			return false
+		}
+		breaker.state = CircuitHalfOpen
+		breaker.probing = true
+		return true
+	case CircuitHalfOpen:
+		if breaker.probing {
+			return false
+		}
+		breaker.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// done records the result of a request allowed by allow.
+func (breaker *CircuitBreaker) done(ctx context.Context, err error) {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	if breaker.policy.FailureThreshold <= 0 {
+		return
+	}
+
+	switch {
+	case err != nil && ctx.Err() != nil:
+		// The request was cancelled by the client, which tells nothing about
+		// the provider.
+		breaker.probing = false
+	case isProviderFailure(err):
+		breaker.failures++
+		breaker.probing = false
+		if breaker.state == CircuitHalfOpen || breaker.failures >= breaker.policy.FailureThreshold {
+			breaker.state = CircuitOpen
+			breaker.openedAt = breaker.now()
+		}
+	default:
+		breaker.state = CircuitClosed
+		breaker.failures = 0
+		breaker.probing = false
+	}
+}
+
+// isProviderFailure returns true if err shows the provider is failing, as
+// opposed to an error specific to the request, such as an unknown repository.
+func isProviderFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if upstreamErr, ok := err.(*UpstreamError); ok {
+		return upstreamErr.Kind == UpstreamServerError
+	}
+
+	return true
+}
+
+// CircuitBreakerStatus holds the state of a circuit breaker.
+type CircuitBreakerStatus struct {
+	Name                string
+	State               string
+	ConsecutiveFailures int
+	// RetryAt is when the next probe request is allowed, if the circuit is
+	// open.
+	RetryAt *time.Time `json:",omitempty"`
+}
+
+// Status returns the current state of the circuit breaker.
+func (breaker *CircuitBreaker) Status() CircuitBreakerStatus {
+	breaker.mutex.Lock()
+	defer breaker.mutex.Unlock()
+
+	status := CircuitBreakerStatus{
+		Name:                breaker.name,
+		State:               breaker.state.String(),
+		ConsecutiveFailures: breaker.failures,
+	}
+	if breaker.state == CircuitOpen {
+		retryAt := breaker.openedAt.Add(breaker.policy.OpenDuration)
+		status.RetryAt = &retryAt
+	}
+
+	return status
+}
+
+// circuitBreakerProvider guards a provider with a circuit breaker.
+type circuitBreakerProvider struct {
+	provider Provider
+	breaker  *CircuitBreaker
+}
+
+func (guarded *circuitBreakerProvider) RetrievePullRequestInfo(ctx context.Context, request BadgeRequest) (PullRequestsInfo, error) {
+	if !guarded.breaker.allow() {
+		return PullRequestsInfo{}, ErrCircuitOpen
+	}
+	info, err := guarded.provider.RetrievePullRequestInfo(ctx, request)
+	guarded.breaker.done(ctx, err)
+	return info, err
+}
+
+func (guarded *circuitBreakerProvider) RetrieveBuildInfo(ctx context.Context, request BadgeRequest) (BuildInfo, error) {
+	if !guarded.breaker.allow() {
+		return BuildInfo{}, ErrCircuitOpen
+	}
+	info, err := guarded.provider.RetrieveBuildInfo(ctx, request)
+	guarded.breaker.done(ctx, err)
+	return info, err
+}
+
+func (guarded *circuitBreakerProvider) RetrieveCommitsInfo(ctx context.Context, request BadgeRequest) (CommitsInfo, error) {
+	if !guarded.breaker.allow() {
+		return CommitsInfo{}, ErrCircuitOpen
+	}
+	info, err := guarded.provider.RetrieveCommitsInfo(ctx, request)
+	guarded.breaker.done(ctx, err)
+	return info, err
+}
+
+func (guarded *circuitBreakerProvider) RetrieveBranchesInfo(ctx context.Context, request BadgeRequest) (BranchesInfo, error) {
+	if !guarded.breaker.allow() {
+		return BranchesInfo{}, ErrCircuitOpen
+	}
+	info, err := guarded.provider.RetrieveBranchesInfo(ctx, request)
+	guarded.breaker.done(ctx, err)
+	return info, err
+}
+
+func (guarded *circuitBreakerProvider) RetrieveIssuesInfo(ctx context.Context, request BadgeRequest) (IssuesInfo, error) {
+	if !guarded.breaker.allow() {
+		return IssuesInfo{}, ErrCircuitOpen
+	}
+	info, err := guarded.provider.RetrieveIssuesInfo(ctx, request)
+	guarded.breaker.done(ctx, err)
+	return info, err
+}
+
+func (guarded *circuitBreakerProvider) RetrieveTagsInfo(ctx context.Context, request BadgeRequest) (TagsInfo, error) {
+	if !guarded.breaker.allow() {
+		return TagsInfo{}, ErrCircuitOpen
+	}
+	info, err := guarded.provider.RetrieveTagsInfo(ctx, request)
+	guarded.breaker.done(ctx, err)
+	return info, err
+}
+
+func (guarded *circuitBreakerProvider) RetrieveRepositories(ctx context.Context, request BadgeRequest) ([]string, error) {
+	if !guarded.breaker.allow() {
+		return nil, ErrCircuitOpen
+	}
+	repositories, err := guarded.provider.RetrieveRepositories(ctx, request)
+	guarded.breaker.done(ctx, err)
+	return repositories, err
+}
@@ -0,0 +1,129 @@
+package bitbadger
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2020, 12, 14, 10, 0, 0, 0, time.UTC)
+	breaker := NewCircuitBreaker("test", CircuitBreakerPolicy{FailureThreshold: 2, OpenDuration: time.Minute})
+	breaker.now = func() time.Time { return now }
+
+	serverError := &UpstreamError{Kind: UpstreamServerError, StatusCode: 503}
+	notFound := &UpstreamError{Kind: UpstreamNotFound, StatusCode: 404}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	steps := []struct {
+		elapsed       time.Duration
+		ctx           context.Context
+		err           error
+		expectedAllow bool
+		expectedState CircuitState
+	}{
+		{0, context.Background(), serverError, true, CircuitClosed},
+		// Request errors don't count as failures.
+		{0, context.Background(), notFound, true, CircuitClosed},
+		{0, context.Background(), serverError, true, CircuitClosed},
+		{0, context.Background(), errors.New("Connection refused"), true, CircuitOpen},
+		{30 * time.Second, context.Background(), nil, false, CircuitOpen},
+		// A failing probe opens the circuit again.
+		{30 * time.Second, context.Background(), serverError, true, CircuitOpen},
+		// A cancelled probe lets another probe through.
+		{time.Minute, cancelled, context.Canceled, true, CircuitHalfOpen},
+		{0, context.Background(), nil, true, CircuitClosed},
+	}
+
+	for i, step := range steps {
+		now = now.Add(step.elapsed)
+		allowed := breaker.allow()
+		if allowed != step.expectedAllow {
+			t.Errorf("allow: Expected %v at step %d, got %v", step.expectedAllow, i, allowed)
+		}
+		if allowed {
+			breaker.done(step.ctx, step.err)
+		}
+		if breaker.state != step.expectedState {
+			t.Errorf("done: Expected state %s at step %d, got %s", step.expectedState, i, breaker.state)
+		}
+	}
+}
+
+func TestCircuitBreakerHalfOpenSingleProbe(t *testing.T) {
+	now := time.Date(2020, 12, 14, 10, 0, 0, 0, time.UTC)
+	breaker := NewCircuitBreaker("test", CircuitBreakerPolicy{FailureThreshold: 1, OpenDuration: time.Minute})
+	breaker.now = func() time.Time { return now }
+
+	breaker.allow()
+	breaker.done(context.Background(), errors.New("Connection refused"))
+
+	now = now.Add(time.Minute)
+	if !breaker.allow() {
+		t.Errorf("allow: The first probe should be allowed")
+	}
+	if breaker.allow() {
+		t.Errorf("allow: A single probe should be allowed at a time")
+	}
+}
+
+func TestServerCircuitOpen(t *testing.T) {
+	// Results are cached, but immediately stale.
+	provider := &fakeProvider{openCount: 3}
+	server := NewServer(
+		WithProvider(provider),
+		WithRenderer(LocalRenderer{}),
+		WithCache(NewCache(CachePolicy{ValidityDuration: time.Nanosecond, MaxCachedResults: 10})),
+		WithCircuitBreakerPolicy(CircuitBreakerPolicy{FailureThreshold: 2, OpenDuration: time.Minute}))
+
+	serve := func(url string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, httptest.NewRequest("GET", url, nil))
+		return recorder
+	}
+
+	serve("/user/repo/open-pr-count")
+
+	provider.err = &UpstreamError{Kind: UpstreamServerError, StatusCode: 503}
+	for i := 0; i < 2; i++ {
+		if recorder := serve("/user/repo/open-pr-count"); recorder.Code != http.StatusBadGateway {
+			t.Errorf("ServeHTTP: Expected status %d while failing, got %d", http.StatusBadGateway, recorder.Code)
+		}
+	}
+
+	requests := provider.requests
+	cases := []struct {
+		url             string
+		expectedStatus  int
+		expectedContent string
+		expectedStale   bool
+	}{
+		{"/user/repo/open-pr-count", http.StatusOK, ">3</text>", true},
+		{"/user/other/open-pr-count", http.StatusOK, ">unavailable</text>", false},
+		{"/user/other/open-pr-count?lang=fr", http.StatusOK, ">indisponible</text>", false},
+		{"/user/other/open-pr-count.json", http.StatusServiceUnavailable, "unavailable", false},
+		{StatusPath, http.StatusOK, `"State":"open"`, false},
+	}
+
+	for _, c := range cases {
+		recorder := serve(c.url)
+		if recorder.Code != c.expectedStatus {
+			t.Errorf("ServeHTTP: Expected status %d for '%s', got %d", c.expectedStatus, c.url, recorder.Code)
+		}
+		if !strings.Contains(recorder.Body.String(), c.expectedContent) {
+			t.Errorf("ServeHTTP: Expected '%s' in the response to '%s', got '%s'", c.expectedContent, c.url, recorder.Body.String())
+		}
+		if stale := recorder.Header().Get("Warning") != ""; stale != c.expectedStale {
+			t.Errorf("ServeHTTP: Expected stale %v for '%s', got %v", c.expectedStale, c.url, stale)
+		}
+	}
+
+	if provider.requests != requests {
+		t.Errorf("ServeHTTP: The provider should not be queried while the circuit is open")
+	}
+}
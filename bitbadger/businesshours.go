@@ -0,0 +1,250 @@
+package bitbadger
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// BusinessHoursPolicy holds the working schedule used to compute PR ages and
+// merge times in working hours only.
+type BusinessHoursPolicy struct {
+	// Enabled computes durations in working hours instead of wall-clock time.
+	Enabled bool
+	// Location is the timezone of the working schedule. Defaults to UTC.
+	Location *time.Location
+	// WorkDays are the days of the work week.
+	WorkDays []time.Weekday
+	// DayStart and DayEnd are the start and end of the working day, as
+	// offsets from midnight.
+	DayStart time.Duration
+	DayEnd   time.Duration
+	// Holidays are non-working days, formatted as "2006-01-02".
+	Holidays []string
+}
+
+// DefaultWorkDays are the days of the default work week.
+var DefaultWorkDays = []time.Weekday{
+	time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday,
+}
+
+// Default start and end of the working day.
+const (
+	DefaultDayStart = 9 * time.Hour
+	DefaultDayEnd   = 17 * time.Hour
+)
+
+var businessHoursPolicy = defaultBusinessHoursPolicy()
+
+// defaultBusinessHoursPolicy returns the business hours policy used when none
+// is configured, which counts durations in wall-clock time.
+func defaultBusinessHoursPolicy() BusinessHoursPolicy {
+	return BusinessHoursPolicy{
+		Location: time.UTC,
+		WorkDays: DefaultWorkDays,
+		DayStart: DefaultDayStart,
+		DayEnd:   DefaultDayEnd,
+	}
+}
+
+// SetBusinessHoursPolicy sets the global business hours policy. It returns an
+// error if the working day or holidays are invalid, in which case the current
+// policy is left untouched.
+func SetBusinessHoursPolicy(policy BusinessHoursPolicy) error {
+	err := policy.Validate()
+	if err != nil {
+		return err
+	}
+
+	businessHoursPolicy = policy
+	return nil
+}
+
+// GetBusinessHoursPolicy returns the current global business hours policy.
+func GetBusinessHoursPolicy() BusinessHoursPolicy {
+	return businessHoursPolicy
+}
+
+// Validate returns an error if the working day or holidays are invalid.
+func (policy BusinessHoursPolicy) Validate() error {
+	_, err := newBusinessSchedule(policy)
+	return err
+}
+
+// businessSchedule computes durations according to a business hours policy.
+type businessSchedule struct {
+	BusinessHoursPolicy
+	workDays map[time.Weekday]bool
+	holidays map[string]bool
+}
+
+// newBusinessSchedule returns the schedule of a business hours policy, or an
+// error if its working day or holidays are invalid.
+func newBusinessSchedule(policy BusinessHoursPolicy) (*businessSchedule, error) {
+	if policy.Location == nil {
+		policy.Location = time.UTC
+	}
+
+	if policy.DayStart < 0 || policy.DayEnd > 24*time.Hour || policy.DayStart >= policy.DayEnd {
+		return nil, errors.New("The working day must start before it ends, within 24 hours")
+	}
+
+	workDays := map[time.Weekday]bool{}
+	for _, day := range policy.WorkDays {
+		workDays[day] = true
+	}
+
+	holidays := map[string]bool{}
+	for _, holiday := range policy.Holidays {
+		_, err := time.Parse("2006-01-02", holiday)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid holiday '%s', expected YYYY-MM-DD", holiday)
+		}
+		holidays[holiday] = true
+	}
+
+	return &businessSchedule{
+		BusinessHoursPolicy: policy,
+		workDays:            workDays,
+		holidays:            holidays,
+	}, nil
+}
+
+// ParseWorkDays parses a comma separated list of days, such as "mon,tue,wed".
+// Day names are case insensitive, and can be abbreviated to three letters.
+func ParseWorkDays(value string) ([]time.Weekday, error) {
+	days := []time.Weekday{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if len(name) < 3 {
+			return nil, fmt.Errorf("Invalid day '%s'", name)
+		}
+
+		found := false
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			dayName := strings.ToLower(day.String())
+			if strings.HasPrefix(dayName, name) {
+				days = append(days, day)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("Invalid day '%s'", name)
+		}
+	}
+
+	return days, nil
+}
+
+// ParseWorkHours parses working hours such as "09:00-17:30", and returns the
+// start and end of the working day as offsets from midnight.
+func ParseWorkHours(value string) (time.Duration, time.Duration, error) {
+	bounds := strings.Split(value, "-")
+	if len(bounds) != 2 {
+		return 0, 0, fmt.Errorf("Invalid working hours '%s', expected HH:MM-HH:MM", value)
+	}
+
+	offsets := [2]time.Duration{}
+	for i, bound := range bounds {
+		var hours, minutes int
+		_, err := fmt.Sscanf(strings.TrimSpace(bound), "%d:%d", &hours, &minutes)
+		if err != nil || hours < 0 || hours > 24 || minutes < 0 || minutes > 59 {
+			return 0, 0, fmt.Errorf("Invalid working hours '%s', expected HH:MM-HH:MM", value)
+		}
+		offsets[i] = time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute
+	}
+
+	return offsets[0], offsets[1], nil
+}
+
+// LoadHolidays reads a list of holidays from a file, with one YYYY-MM-DD date
+// per line. Empty lines and lines starting with '#' are ignored.
+func LoadHolidays(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	dates := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		dates = append(dates, line)
+	}
+
+	return dates, scanner.Err()
+}
+
+// elapsed returns the duration between start and end, counted in working hours
+// only if business hours are enabled.
+func (schedule *businessSchedule) elapsed(start time.Time, end time.Time) time.Duration {
+	if !schedule.Enabled {
+		return end.Sub(start)
+	}
+
+	return schedule.businessDuration(start, end)
+}
+
+// businessDuration returns the working time between start and end, according
+// to the business hours policy.
+func (schedule *businessSchedule) businessDuration(start time.Time, end time.Time) time.Duration {
+	if !end.After(start) {
+		return 0
+	}
+
+	location := schedule.Location
+	start = start.In(location)
+	end = end.In(location)
+
+	total := time.Duration(0)
+	year, month, day := start.Date()
+	for date := time.Date(year, month, day, 0, 0, 0, 0, location); date.Before(end); date = date.AddDate(0, 0, 1) {
+		if !schedule.workDays[date.Weekday()] || schedule.holidays[date.Format("2006-01-02")] {
+			continue
+		}
+
+		// Offsets are applied to the wall clock to be correct across daylight
+		// saving time changes.
+		dayStart := wallClock(date, schedule.DayStart)
+		dayEnd := wallClock(date, schedule.DayEnd)
+		if dayStart.Before(start) {
+			dayStart = start
+		}
+		if dayEnd.After(end) {
+			dayEnd = end
+		}
+
+		if dayEnd.After(dayStart) {
+			total += dayEnd.Sub(dayStart)
+		}
+	}
+
+	return total
+}
+
+// wallClock returns the time of date at offset from midnight, on the wall
+// clock of date's location.
+func wallClock(date time.Time, offset time.Duration) time.Time {
+	hours := int(offset / time.Hour)
+	minutes := int((offset % time.Hour) / time.Minute)
+	return time.Date(date.Year(), date.Month(), date.Day(), hours, minutes, 0, 0, date.Location())
+}
+
+// durationLabel returns the label of a duration badge, marked when durations
+// are counted in working hours.
+func (factory badgeFactory) durationLabel(key string, localizer Localizer) string {
+	label := localizer.Text(key)
+	if factory.workHours {
+		return localizer.Text("label.work-hours", label)
+	}
+
+	return label
+}
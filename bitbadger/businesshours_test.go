@@ -0,0 +1,161 @@
+package bitbadger
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestBusinessDuration(t *testing.T) {
+	policy := BusinessHoursPolicy{
+		Enabled:  true,
+		WorkDays: DefaultWorkDays,
+		DayStart: DefaultDayStart,
+		DayEnd:   DefaultDayEnd,
+		Holidays: []string{"2020-12-25"},
+	}
+	schedule, err := newBusinessSchedule(policy)
+	if err != nil {
+		t.Fatalf("newBusinessSchedule: Unexpected error: %s", err)
+	}
+
+	parse := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatalf("Invalid test time %s", value)
+		}
+		return parsed
+	}
+
+	cases := []struct {
+		start    string
+		end      string
+		expected time.Duration
+	}{
+		// Friday evening to Monday morning
+		{"2020-12-11T18:00:00Z", "2020-12-14T08:00:00Z", 0},
+		// Friday afternoon to Monday noon
+		{"2020-12-11T15:00:00Z", "2020-12-14T12:00:00Z", 5 * time.Hour},
+		// Within a single day
+		{"2020-12-14T10:00:00Z", "2020-12-14T11:30:00Z", 90 * time.Minute},
+		// A full week
+		{"2020-12-14T00:00:00Z", "2020-12-21T00:00:00Z", 40 * time.Hour},
+		// Week with a holiday on Friday
+		{"2020-12-21T00:00:00Z", "2020-12-28T00:00:00Z", 32 * time.Hour},
+		// End before start
+		{"2020-12-14T11:00:00Z", "2020-12-14T10:00:00Z", 0},
+	}
+
+	for _, c := range cases {
+		duration := schedule.elapsed(parse(c.start), parse(c.end))
+		if duration != c.expected {
+			t.Errorf("elapsed: Expected %s from %s to %s, got %s", c.expected, c.start, c.end, duration)
+		}
+	}
+
+	factory := badgeFactory{thresholds: DefaultThresholdPolicy(), workHours: true}
+	if factory.durationLabel("label.oldest-open-pr-age", DefaultLocalizer()) != "Oldest PR age (work hours)" {
+		t.Errorf("durationLabel: Should mark labels when business hours are enabled")
+	}
+
+	location, err := time.LoadLocation("Europe/Paris")
+	if err == nil {
+		policy.Location = location
+		schedule, err := newBusinessSchedule(policy)
+		if err != nil {
+			t.Fatalf("newBusinessSchedule: Unexpected error: %s", err)
+		}
+
+		// 9:00 to 17:00 in Paris is 8:00 to 16:00 UTC in winter
+		duration := schedule.elapsed(parse("2020-12-14T00:00:00Z"), parse("2020-12-14T12:00:00Z"))
+		if duration != 4*time.Hour {
+			t.Errorf("elapsed: Expected 4h in Europe/Paris, got %s", duration)
+		}
+	}
+
+	schedule, err = newBusinessSchedule(defaultBusinessHoursPolicy())
+	if err != nil {
+		t.Fatalf("newBusinessSchedule: Unexpected error: %s", err)
+	}
+	if schedule.elapsed(parse("2020-12-11T18:00:00Z"), parse("2020-12-14T08:00:00Z")) != 62*time.Hour {
+		t.Errorf("elapsed: Should use wall-clock time when business hours are disabled")
+	}
+	factory.workHours = false
+	if factory.durationLabel("label.oldest-open-pr-age", DefaultLocalizer()) != "Oldest PR age" {
+		t.Errorf("durationLabel: Should not mark labels when business hours are disabled")
+	}
+}
+
+func TestSetBusinessHoursPolicyErrors(t *testing.T) {
+	policies := []BusinessHoursPolicy{
+		{DayStart: 17 * time.Hour, DayEnd: 9 * time.Hour},
+		{DayStart: 9 * time.Hour, DayEnd: 25 * time.Hour},
+		{DayStart: DefaultDayStart, DayEnd: DefaultDayEnd, Holidays: []string{"25/12/2020"}},
+	}
+
+	for _, policy := range policies {
+		if SetBusinessHoursPolicy(policy) == nil {
+			t.Errorf("SetBusinessHoursPolicy: Should generate an error for %v", policy)
+		}
+	}
+}
+
+func TestParseWorkDays(t *testing.T) {
+	days, err := ParseWorkDays("Sun, mon,tuesday,wed,thu")
+	if err != nil {
+		t.Fatalf("ParseWorkDays: Unexpected error: %s", err)
+	}
+
+	expected := []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday}
+	if len(days) != len(expected) {
+		t.Fatalf("ParseWorkDays: Expected %v, got %v", expected, days)
+	}
+	for i := range days {
+		if days[i] != expected[i] {
+			t.Errorf("ParseWorkDays: Expected %v, got %v", expected, days)
+		}
+	}
+
+	for _, value := range []string{"mo", "mon,funday", ""} {
+		if _, err := ParseWorkDays(value); err == nil {
+			t.Errorf("ParseWorkDays: Should generate an error for '%s'", value)
+		}
+	}
+}
+
+func TestParseWorkHours(t *testing.T) {
+	start, end, err := ParseWorkHours("08:30-17:00")
+	if err != nil || start != 8*time.Hour+30*time.Minute || end != 17*time.Hour {
+		t.Errorf("ParseWorkHours: Unexpected result %s, %s, %v", start, end, err)
+	}
+
+	for _, value := range []string{"8-17", "08:00", "08:00-17:75", "a:00-17:00"} {
+		if _, _, err := ParseWorkHours(value); err == nil {
+			t.Errorf("ParseWorkHours: Should generate an error for '%s'", value)
+		}
+	}
+}
+
+func TestLoadHolidays(t *testing.T) {
+	file, err := ioutil.TempFile("", "holidays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	file.WriteString("# Christmas\n2020-12-25\n\n  2021-01-01  \n")
+	file.Close()
+
+	dates, err := LoadHolidays(file.Name())
+	if err != nil {
+		t.Fatalf("LoadHolidays: Unexpected error: %s", err)
+	}
+	if len(dates) != 2 || dates[0] != "2020-12-25" || dates[1] != "2021-01-01" {
+		t.Errorf("LoadHolidays: Unexpected dates %v", dates)
+	}
+
+	if _, err := LoadHolidays(file.Name() + ".missing"); err == nil {
+		t.Errorf("LoadHolidays: Should generate an error for missing files")
+	}
+}
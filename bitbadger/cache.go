@@ -0,0 +1,233 @@
+package bitbadger
+
+import (
+	"sync"
+	"time"
+)
+
+// CachePolicy holds information used to check if a cache entry is still valid.
+type CachePolicy struct {
+	ValidityDuration time.Duration
+	MaxCachedResults int
+	// LongTermValidityDuration applies instead of ValidityDuration to
+	// expensive badge types, see longTermCachedTypes.
+	LongTermValidityDuration time.Duration
+}
+
+// DefaultCachePolicy is the policy of caches created without one.
+var DefaultCachePolicy = CachePolicy{
+	ValidityDuration:         10 * time.Minute,
+	MaxCachedResults:         100,
+	LongTermValidityDuration: 12 * time.Hour,
+}
+
+// longTermCachedTypes lists the badge types expensive to generate, which are
+// cached using the long term validity duration.
+var longTermCachedTypes = []BadgeType{
+	ContributorsType,
+	BusFactorType,
+}
+
+// CacheEntry holds the request, result and last time refreshed.
+type CacheEntry struct {
+	Request     BadgeRequest
+	ImageResult *BadgeImage
+	RefreshTime time.Time
+}
+
+// Cache holds the results of badge requests. It is safe for concurrent use.
+type Cache struct {
+	mutex   sync.Mutex
+	policy  CachePolicy
+	entries map[BadgeRequest]CacheEntry
+	stats   CacheStats
+}
+
+// CacheStats holds the usage statistics of a cache.
+type CacheStats struct {
+	// Size is the number of cached results.
+	Size int
+	// Hits and Misses count the lookups finding a valid result or not.
+	Hits   int
+	Misses int
+	// Evictions counts the results removed to stay under the maximum number
+	// of cached results.
+	Evictions int
+}
+
+// NewCache returns an empty cache using policy.
+func NewCache(policy CachePolicy) *Cache {
+	return &Cache{
+		policy:  policy,
+		entries: make(map[BadgeRequest]CacheEntry),
+	}
+}
+
+// defaultCache is the cache used by the package level functions.
+var defaultCache = NewCache(DefaultCachePolicy)
+
+// Clear clears the full cache content.
+func (cache *Cache) Clear() {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.entries = make(map[BadgeRequest]CacheEntry)
+}
+
+// SetPolicy sets the cache policy.
+func (cache *Cache) SetPolicy(policy CachePolicy) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.policy = policy
+}
+
+// Policy returns the cache policy.
+func (cache *Cache) Policy() CachePolicy {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	return cache.policy
+}
+
+// Store caches the result to a request and sets it as refreshed "Now()".
+func (cache *Cache) Store(request BadgeRequest, image *BadgeImage) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	// Don't cache request if cache is disabled
+	if cache.validityDuration(request) == 0 {
+		return
+	}
+
+	cache.entries[request] = CacheEntry{
+		Request:     request,
+		ImageResult: image,
+		RefreshTime: time.Now(),
+	}
+
+	cache.cleanup()
+}
+
+func (cache *Cache) cleanup() {
+	if cache.policy.MaxCachedResults <= 0 && len(cache.entries) > 0 {
+		cache.stats.Evictions += len(cache.entries)
+		cache.entries = make(map[BadgeRequest]CacheEntry)
+	}
+
+	// Find and remove oldest entry, until below threshold.
+	// Will definitely need a better implementation :)
+	for len(cache.entries) > cache.policy.MaxCachedResults {
+		var oldestRequest BadgeRequest
+		var oldestRefreshTime time.Time
+		init := false
+		for request, entry := range cache.entries {
+			if !init || oldestRefreshTime.After(entry.RefreshTime) {
+				init = true
+				oldestRefreshTime = entry.RefreshTime
+				oldestRequest = request
+			}
+		}
+
+		delete(cache.entries, oldestRequest)
+		cache.stats.Evictions++
+	}
+}
+
+// entryValid returns true if the cache entry is valid.
+func (cache *Cache) entryValid(entry *CacheEntry) bool {
+	if entry == nil {
+		return false
+	}
+
+	return time.Since(entry.RefreshTime) < cache.validityDuration(entry.Request)
+}
+
+// validityDuration returns how long the result of request remains valid once
+// cached.
+func (cache *Cache) validityDuration(request BadgeRequest) time.Duration {
+	for _, longTermType := range longTermCachedTypes {
+		if request.Type == longTermType {
+			return cache.policy.LongTermValidityDuration
+		}
+	}
+
+	return cache.policy.ValidityDuration
+}
+
+// Cached returns true if the request is cached and valid.
+func (cache *Cache) Cached(request BadgeRequest) bool {
+	return cache.Get(request) != nil
+}
+
+// Get returns the cached result for the request or nil if the request is not
+// cached, or if the cached result is not valid.
+func (cache *Cache) Get(request BadgeRequest) *BadgeImage {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	entry, cached := cache.entries[request]
+	if !cached || !cache.entryValid(&entry) {
+		cache.stats.Misses++
+		return nil
+	}
+
+	cache.stats.Hits++
+	return entry.ImageResult
+}
+
+// Stats returns the usage statistics of the cache.
+func (cache *Cache) Stats() CacheStats {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	stats := cache.stats
+	stats.Size = len(cache.entries)
+	return stats
+}
+
+// GetStale returns the result cached for the request, even if it is no longer
+// valid, or nil if there is none.
+func (cache *Cache) GetStale(request BadgeRequest) *BadgeImage {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	entry, cached := cache.entries[request]
+	if !cached {
+		return nil
+	}
+
+	return entry.ImageResult
+}
+
+// ClearCache clears the full content of the global cache.
+func ClearCache() {
+	defaultCache.Clear()
+}
+
+// SetCachePolicy sets the global cache policy.
+func SetCachePolicy(policy CachePolicy) {
+	defaultCache.SetPolicy(policy)
+}
+
+// GetCachePolicy returns the current global cache policy.
+func GetCachePolicy() CachePolicy {
+	return defaultCache.Policy()
+}
+
+// CacheRequestResult caches the result to a request in the global cache.
+func CacheRequestResult(request BadgeRequest, image *BadgeImage) {
+	defaultCache.Store(request, image)
+}
+
+// RequestCached returns true if the request is cached and valid in the global
+// cache.
+func RequestCached(request BadgeRequest) bool {
+	return defaultCache.Cached(request)
+}
+
+// GetCachedResult returns the result cached for the request in the global
+// cache, or nil if there is none or if it is not valid.
+func GetCachedResult(request BadgeRequest) *BadgeImage {
+	return defaultCache.Get(request)
+}
@@ -176,3 +176,81 @@ func TestCacheMaxCount(t *testing.T) {
 		t.Errorf("RequestCached: Request3 should not be cached anymore")
 	}
 }
+
+func TestCacheLongTermValidityDuration(t *testing.T) {
+	defer SetCachePolicy(GetCachePolicy())
+
+	ClearCache()
+	SetCachePolicy(CachePolicy{
+		ValidityDuration:         0,
+		MaxCachedResults:         100,
+		LongTermValidityDuration: 10 * time.Minute,
+	})
+
+	request := BadgeRequest{
+		Username:   "test",
+		Repository: "repo",
+		Type:       OpenPRCountType,
+	}
+	longTermRequest := BadgeRequest{
+		Username:   "test",
+		Repository: "repo",
+		Type:       BusFactorType,
+	}
+
+	CacheRequestResult(request, &BadgeImage{})
+	CacheRequestResult(longTermRequest, &BadgeImage{})
+
+	if RequestCached(request) {
+		t.Errorf("RequestCached: Request should not be cached when caching is disabled")
+	}
+	if !RequestCached(longTermRequest) {
+		t.Errorf("RequestCached: Expensive request should be cached for the long term")
+	}
+}
+
+func TestCacheInstances(t *testing.T) {
+	cache1 := NewCache(DefaultCachePolicy)
+	cache2 := NewCache(CachePolicy{})
+
+	request := BadgeRequest{
+		Username:   "test",
+		Repository: "repo",
+		Type:       OpenPRCountType,
+	}
+
+	cache1.Store(request, &BadgeImage{})
+	cache2.Store(request, &BadgeImage{})
+
+	if !cache1.Cached(request) {
+		t.Errorf("Cached: Request should be cached")
+	}
+	if cache2.Cached(request) {
+		t.Errorf("Cached: Request should not be cached when caching is disabled")
+	}
+
+	cache1.Clear()
+	if cache1.Get(request) != nil {
+		t.Errorf("Get: Request should not be cached once cleared")
+	}
+}
+
+func TestCacheStats(t *testing.T) {
+	cache := NewCache(CachePolicy{ValidityDuration: time.Minute, MaxCachedResults: 2})
+	requests := []BadgeRequest{
+		{Username: "user", Repository: "repo1", Type: OpenPRCountType},
+		{Username: "user", Repository: "repo2", Type: OpenPRCountType},
+		{Username: "user", Repository: "repo3", Type: OpenPRCountType},
+	}
+
+	cache.Get(requests[0])
+	for _, request := range requests {
+		cache.Store(request, &BadgeImage{})
+	}
+	cache.Get(requests[2])
+
+	expected := CacheStats{Size: 2, Hits: 1, Misses: 1, Evictions: 1}
+	if stats := cache.Stats(); stats != expected {
+		t.Errorf("Stats: Expected %+v, got %+v", expected, stats)
+	}
+}
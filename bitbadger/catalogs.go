@@ -0,0 +1,292 @@
+package bitbadger
+
+// englishCatalog holds all the badge strings, and is the fallback of other
+// catalogs. Keys starting with "summary." are the short labels of summary
+// parts, named after their badge type.
+var englishCatalog = Catalog{
+	"label.open-prs":           "Open PRs",
+	"label.draft-prs":          "Draft PRs",
+	"label.awaiting-review":    "PRs awaiting review",
+	"label.avg-reviewers":      "Avg. reviewers per PR",
+	"label.pending-reviews":    "Pending reviews",
+	"label.merged-prs":         "Merged PRs",
+	"label.open-pr-avg-age":    "Avg. current PRs age",
+	"label.oldest-open-pr-age": "Oldest PR age",
+	"label.avg-pr-merge-time":  "Avg. PR merge time",
+	"label.work-hours":         "%s (work hours)",
+	"label.build":              "Build",
+	"label.pr-builds-passing":  "PR builds passing",
+	"label.last-commit":        "Last commit",
+	"label.commit-activity":    "Commit activity",
+	"label.contributors":       "Contributors",
+	"label.bus-factor":         "Bus factor",
+	"label.stale-branches":     "Stale branches",
+	"label.open-issues":        "Open issues",
+	"label.oldest-issue-age":   "Oldest issue age",
+	"label.latest-tag":         "Latest tag",
+	"label.health":             "Health",
+	"label.upstream":           "upstream",
+
+	"message.not-available":  "n/a",
+	"message.none":           "none",
+	"message.ago":            "%s ago",
+	"message.rate":           "%s/%s",
+	"message.unavailable":    "unavailable",
+	"message.no-credentials": "no credentials for this workspace",
+
+	"unit.day":        "day",
+	"unit.days":       "days",
+	"unit.hour":       "hour",
+	"unit.hours":      "hours",
+	"unit.min":        "min",
+	"unit.mins":       "mins",
+	"unit.sec":        "sec",
+	"unit.secs":       "secs",
+	"unit.day.short":  "d",
+	"unit.hour.short": "h",
+	"unit.min.short":  "m",
+	"unit.sec.short":  "s",
+	"unit.week":       "week",
+	"unit.month":      "month",
+
+	"number.decimal": ".",
+
+	"summary.open-pr-count":         "open",
+	"summary.open-pr-avg-age":       "avg. age",
+	"summary.oldest-open-pr-age":    "oldest",
+	"summary.avg-pr-merge-time":     "merge",
+	"summary.draft-pr-count":        "drafts",
+	"summary.awaiting-review-count": "awaiting review",
+	"summary.avg-reviewers-per-pr":  "reviewers",
+	"summary.pending-reviews":       "pending reviews",
+	"summary.merged-prs-per-day":    "merged",
+	"summary.merged-prs-per-week":   "merged",
+	"summary.merged-prs-per-month":  "merged",
+	"summary.build-status":          "build",
+	"summary.pr-build-pass-rate":    "PR builds",
+	"summary.last-commit":           "last commit",
+	"summary.commit-activity":       "commits",
+	"summary.contributors":          "contributors",
+	"summary.bus-factor":            "bus factor",
+	"summary.stale-branches":        "stale branches",
+	"summary.open-issue-count":      "issues",
+	"summary.oldest-open-issue-age": "oldest issue",
+	"summary.latest-tag":            "tag",
+	"summary.health":                "health",
+}
+
+var frenchCatalog = Catalog{
+	"label.open-prs":           "PRs ouvertes",
+	"label.draft-prs":          "PRs brouillons",
+	"label.awaiting-review":    "PRs en attente de revue",
+	"label.avg-reviewers":      "Relecteurs moy. par PR",
+	"label.pending-reviews":    "Revues en attente",
+	"label.merged-prs":         "PRs fusionnées",
+	"label.open-pr-avg-age":    "Âge moy. des PRs",
+	"label.oldest-open-pr-age": "Âge de la plus vieille PR",
+	"label.avg-pr-merge-time":  "Délai moy. de fusion",
+	"label.work-hours":         "%s (heures ouvrées)",
+	"label.build":              "Build",
+	"label.pr-builds-passing":  "Builds de PR réussis",
+	"label.last-commit":        "Dernier commit",
+	"label.commit-activity":    "Activité des commits",
+	"label.contributors":       "Contributeurs",
+	"label.bus-factor":         "Facteur d'autobus",
+	"label.stale-branches":     "Branches inactives",
+	"label.open-issues":        "Tickets ouverts",
+	"label.oldest-issue-age":   "Âge du plus vieux ticket",
+	"label.latest-tag":         "Dernier tag",
+	"label.health":             "Santé",
+	"label.upstream":           "source",
+
+	"message.not-available":  "n/d",
+	"message.none":           "aucun",
+	"message.ago":            "il y a %s",
+	"message.rate":           "%s/%s",
+	"message.unavailable":    "indisponible",
+	"message.no-credentials": "aucun identifiant pour cet espace de travail",
+
+	"unit.day":        "jour",
+	"unit.days":       "jours",
+	"unit.hour":       "heure",
+	"unit.hours":      "heures",
+	"unit.min":        "min",
+	"unit.mins":       "min",
+	"unit.sec":        "s",
+	"unit.secs":       "s",
+	"unit.day.short":  "j",
+	"unit.hour.short": "h",
+	"unit.min.short":  "min",
+	"unit.sec.short":  "s",
+	"unit.week":       "semaine",
+	"unit.month":      "mois",
+
+	"number.decimal": ",",
+
+	"summary.open-pr-count":         "ouvertes",
+	"summary.open-pr-avg-age":       "âge moy.",
+	"summary.oldest-open-pr-age":    "plus vieille",
+	"summary.avg-pr-merge-time":     "fusion",
+	"summary.draft-pr-count":        "brouillons",
+	"summary.awaiting-review-count": "en attente",
+	"summary.avg-reviewers-per-pr":  "relecteurs",
+	"summary.pending-reviews":       "revues",
+	"summary.merged-prs-per-day":    "fusionnées",
+	"summary.merged-prs-per-week":   "fusionnées",
+	"summary.merged-prs-per-month":  "fusionnées",
+	"summary.build-status":          "build",
+	"summary.pr-build-pass-rate":    "builds de PR",
+	"summary.last-commit":           "dernier commit",
+	"summary.commit-activity":       "commits",
+	"summary.contributors":          "contributeurs",
+	"summary.bus-factor":            "facteur d'autobus",
+	"summary.stale-branches":        "branches inactives",
+	"summary.open-issue-count":      "tickets",
+	"summary.oldest-open-issue-age": "plus vieux ticket",
+	"summary.latest-tag":            "tag",
+	"summary.health":                "santé",
+}
+
+var germanCatalog = Catalog{
+	"label.open-prs":           "Offene PRs",
+	"label.draft-prs":          "PR-Entwürfe",
+	"label.awaiting-review":    "PRs ohne Review",
+	"label.avg-reviewers":      "Ø Reviewer pro PR",
+	"label.pending-reviews":    "Ausstehende Reviews",
+	"label.merged-prs":         "Gemergte PRs",
+	"label.open-pr-avg-age":    "Ø Alter offener PRs",
+	"label.oldest-open-pr-age": "Alter der ältesten PR",
+	"label.avg-pr-merge-time":  "Ø Zeit bis zum Merge",
+	"label.work-hours":         "%s (Arbeitszeit)",
+	"label.build":              "Build",
+	"label.pr-builds-passing":  "Erfolgreiche PR-Builds",
+	"label.last-commit":        "Letzter Commit",
+	"label.commit-activity":    "Commit-Aktivität",
+	"label.contributors":       "Mitwirkende",
+	"label.bus-factor":         "Busfaktor",
+	"label.stale-branches":     "Veraltete Branches",
+	"label.open-issues":        "Offene Issues",
+	"label.oldest-issue-age":   "Alter des ältesten Issues",
+	"label.latest-tag":         "Neuester Tag",
+	"label.health":             "Zustand",
+	"label.upstream":           "Quelle",
+
+	"message.not-available":  "k. A.",
+	"message.none":           "keiner",
+	"message.ago":            "%s her",
+	"message.rate":           "%s/%s",
+	"message.unavailable":    "nicht verfügbar",
+	"message.no-credentials": "keine Zugangsdaten für diesen Workspace",
+
+	"unit.day":        "Tag",
+	"unit.days":       "Tage",
+	"unit.hour":       "Stunde",
+	"unit.hours":      "Stunden",
+	"unit.min":        "Min.",
+	"unit.mins":       "Min.",
+	"unit.sec":        "Sek.",
+	"unit.secs":       "Sek.",
+	"unit.day.short":  "T",
+	"unit.hour.short": "Std",
+	"unit.min.short":  "min",
+	"unit.sec.short":  "s",
+	"unit.week":       "Woche",
+	"unit.month":      "Monat",
+
+	"number.decimal": ",",
+
+	"summary.open-pr-count":         "offen",
+	"summary.open-pr-avg-age":       "Ø Alter",
+	"summary.oldest-open-pr-age":    "älteste",
+	"summary.avg-pr-merge-time":     "Merge",
+	"summary.draft-pr-count":        "Entwürfe",
+	"summary.awaiting-review-count": "ohne Review",
+	"summary.avg-reviewers-per-pr":  "Reviewer",
+	"summary.pending-reviews":       "ausstehend",
+	"summary.merged-prs-per-day":    "gemergt",
+	"summary.merged-prs-per-week":   "gemergt",
+	"summary.merged-prs-per-month":  "gemergt",
+	"summary.build-status":          "Build",
+	"summary.pr-build-pass-rate":    "PR-Builds",
+	"summary.last-commit":           "letzter Commit",
+	"summary.commit-activity":       "Commits",
+	"summary.contributors":          "Mitwirkende",
+	"summary.bus-factor":            "Busfaktor",
+	"summary.stale-branches":        "veraltete Branches",
+	"summary.open-issue-count":      "Issues",
+	"summary.oldest-open-issue-age": "ältestes Issue",
+	"summary.latest-tag":            "Tag",
+	"summary.health":                "Zustand",
+}
+
+var spanishCatalog = Catalog{
+	"label.open-prs":           "PRs abiertas",
+	"label.draft-prs":          "PRs en borrador",
+	"label.awaiting-review":    "PRs sin revisar",
+	"label.avg-reviewers":      "Revisores prom. por PR",
+	"label.pending-reviews":    "Revisiones pendientes",
+	"label.merged-prs":         "PRs fusionadas",
+	"label.open-pr-avg-age":    "Antigüedad prom. de PRs",
+	"label.oldest-open-pr-age": "PR más antigua",
+	"label.avg-pr-merge-time":  "Tiempo prom. de fusión",
+	"label.work-hours":         "%s (horas laborables)",
+	"label.build":              "Build",
+	"label.pr-builds-passing":  "Builds de PR exitosos",
+	"label.last-commit":        "Último commit",
+	"label.commit-activity":    "Actividad de commits",
+	"label.contributors":       "Colaboradores",
+	"label.bus-factor":         "Factor bus",
+	"label.stale-branches":     "Ramas inactivas",
+	"label.open-issues":        "Incidencias abiertas",
+	"label.oldest-issue-age":   "Incidencia más antigua",
+	"label.latest-tag":         "Última etiqueta",
+	"label.health":             "Salud",
+	"label.upstream":           "origen",
+
+	"message.not-available":  "n/d",
+	"message.none":           "ninguna",
+	"message.ago":            "hace %s",
+	"message.rate":           "%s/%s",
+	"message.unavailable":    "no disponible",
+	"message.no-credentials": "sin credenciales para este espacio de trabajo",
+
+	"unit.day":        "día",
+	"unit.days":       "días",
+	"unit.hour":       "hora",
+	"unit.hours":      "horas",
+	"unit.min":        "min",
+	"unit.mins":       "min",
+	"unit.sec":        "s",
+	"unit.secs":       "s",
+	"unit.day.short":  "d",
+	"unit.hour.short": "h",
+	"unit.min.short":  "min",
+	"unit.sec.short":  "s",
+	"unit.week":       "semana",
+	"unit.month":      "mes",
+
+	"number.decimal": ",",
+
+	"summary.open-pr-count":         "abiertas",
+	"summary.open-pr-avg-age":       "antigüedad",
+	"summary.oldest-open-pr-age":    "más antigua",
+	"summary.avg-pr-merge-time":     "fusión",
+	"summary.draft-pr-count":        "borradores",
+	"summary.awaiting-review-count": "sin revisar",
+	"summary.avg-reviewers-per-pr":  "revisores",
+	"summary.pending-reviews":       "pendientes",
+	"summary.merged-prs-per-day":    "fusionadas",
+	"summary.merged-prs-per-week":   "fusionadas",
+	"summary.merged-prs-per-month":  "fusionadas",
+	"summary.build-status":          "build",
+	"summary.pr-build-pass-rate":    "builds de PR",
+	"summary.last-commit":           "último commit",
+	"summary.commit-activity":       "commits",
+	"summary.contributors":          "colaboradores",
+	"summary.bus-factor":            "factor bus",
+	"summary.stale-branches":        "ramas inactivas",
+	"summary.open-issue-count":      "incidencias",
+	"summary.oldest-open-issue-age": "incidencia más antigua",
+	"summary.latest-tag":            "etiqueta",
+	"summary.health":                "salud",
+}
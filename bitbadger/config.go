@@ -0,0 +1,47 @@
+package bitbadger
+
+// AuthMethod selects how requests to the upstream repository are
+// authenticated.
+type AuthMethod string
+
+// Authentication methods supported by BitBucket Cloud.
+const (
+	// AppPasswordAuth authenticates with a username and an app password, using
+	// HTTP basic authentication. It is the default.
+	AppPasswordAuth AuthMethod = "app-password"
+	// OAuthAuth authenticates as an OAuth consumer, with the client
+	// credentials grant. Access tokens are requested, and refreshed before
+	// they expire, automatically.
+	OAuthAuth AuthMethod = "oauth"
+	// AccessTokenAuth authenticates with a repository, project or workspace
+	// access token, sent as a bearer token.
+	AccessTokenAuth AuthMethod = "access-token"
+)
+
+// Config holds server configuration to authenticate to the upstream
+// repository.
+type Config struct {
+	// Auth is the authentication method. Defaults to AppPasswordAuth.
+	Auth AuthMethod
+	// Username and Password are the credentials of AppPasswordAuth.
+	Username string
+	Password string
+	// ClientID and ClientSecret are the key and secret of the OAuth consumer
+	// of OAuthAuth.
+	ClientID     string
+	ClientSecret string
+	// AccessToken is the token of AccessTokenAuth.
+	AccessToken string
+}
+
+var config Config
+
+// SetConfig sets the global configuration.
+func SetConfig(conf Config) {
+	config = conf
+}
+
+// GetConfig gets the global configuration.
+func GetConfig() Config {
+	return config
+}
@@ -0,0 +1,91 @@
+package bitbadger
+
+import (
+	"regexp"
+)
+
+// DraftPolicy holds information used to detect draft / work-in-progress pull
+// requests, and how they should be accounted for.
+type DraftPolicy struct {
+	// TitlePatterns are regular expressions matched against PR titles. A PR
+	// whose title matches any of them is considered a draft.
+	TitlePatterns []string
+	// ExcludeDrafts removes draft PRs from all open PR metrics.
+	ExcludeDrafts bool
+}
+
+// DefaultDraftTitlePatterns are the title patterns used to detect draft PRs
+// when none are configured.
+var DefaultDraftTitlePatterns = []string{
+	`(?i)^\s*(\[wip\]|wip\b)`,
+	`(?i)^\s*(\[draft\]|draft:)`,
+}
+
+var draftPolicy = DraftPolicy{
+	TitlePatterns: DefaultDraftTitlePatterns,
+}
+
+// SetDraftPolicy sets the global draft policy. It returns an error if any of
+// the title patterns is not a valid regular expression, in which case the
+// current policy is left untouched.
+func SetDraftPolicy(policy DraftPolicy) error {
+	err := policy.Validate()
+	if err != nil {
+		return err
+	}
+
+	draftPolicy = policy
+	return nil
+}
+
+// GetDraftPolicy returns the current global draft policy.
+func GetDraftPolicy() DraftPolicy {
+	return draftPolicy
+}
+
+// Validate returns an error if any of the title patterns is not a valid
+// regular expression.
+func (policy DraftPolicy) Validate() error {
+	_, err := newDraftDetector(policy)
+	return err
+}
+
+// draftDetector detects draft pull requests according to a draft policy.
+type draftDetector struct {
+	DraftPolicy
+	titleRegexps []*regexp.Regexp
+}
+
+// newDraftDetector returns the detector of a draft policy, or an error if any
+// of its title patterns is invalid.
+func newDraftDetector(policy DraftPolicy) (*draftDetector, error) {
+	regexps := make([]*regexp.Regexp, 0, len(policy.TitlePatterns))
+	for _, pattern := range policy.TitlePatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		regexps = append(regexps, re)
+	}
+
+	return &draftDetector{
+		DraftPolicy:  policy,
+		titleRegexps: regexps,
+	}, nil
+}
+
+// isDraft returns true if the pull request is flagged as draft upstream, or if
+// its title matches one of the draft title patterns.
+func (detector *draftDetector) isDraft(draftFlag bool, title string) bool {
+	if draftFlag {
+		return true
+	}
+
+	for _, re := range detector.titleRegexps {
+		if re.MatchString(title) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,60 @@
+package bitbadger
+
+import (
+	"testing"
+)
+
+func TestSetDraftPolicy(t *testing.T) {
+	defer SetDraftPolicy(DraftPolicy{TitlePatterns: DefaultDraftTitlePatterns})
+
+	newPolicy := DraftPolicy{
+		TitlePatterns: []string{"^DNM"},
+		ExcludeDrafts: true,
+	}
+	err := SetDraftPolicy(newPolicy)
+	if err != nil {
+		t.Errorf("SetDraftPolicy: Should not generate an error")
+	}
+
+	currentPolicy := GetDraftPolicy()
+	if !currentPolicy.ExcludeDrafts || len(currentPolicy.TitlePatterns) != 1 {
+		t.Errorf("Set/GetDraftPolicy: Draft policies differ")
+	}
+
+	err = SetDraftPolicy(DraftPolicy{TitlePatterns: []string{"("}})
+	if err == nil {
+		t.Errorf("SetDraftPolicy: Should generate an error for invalid patterns")
+	}
+	if GetDraftPolicy().TitlePatterns[0] != "^DNM" {
+		t.Errorf("SetDraftPolicy: Policy should be unchanged after an error")
+	}
+}
+
+func TestIsDraft(t *testing.T) {
+	detector, err := newDraftDetector(DraftPolicy{TitlePatterns: DefaultDraftTitlePatterns})
+	if err != nil {
+		t.Fatalf("newDraftDetector: Unexpected error: %s", err)
+	}
+
+	cases := []struct {
+		draftFlag bool
+		title     string
+		expected  bool
+	}{
+		{true, "Add feature", true},
+		{false, "Add feature", false},
+		{false, "WIP: Add feature", true},
+		{false, "[WIP] Add feature", true},
+		{false, "wip add feature", true},
+		{false, "Draft: Add feature", true},
+		{false, "[Draft] Add feature", true},
+		{false, "Wipe cache on startup", false},
+		{false, "Drafting support for templates", false},
+	}
+
+	for _, c := range cases {
+		if detector.isDraft(c.draftFlag, c.title) != c.expected {
+			t.Errorf("isDraft: Expected %t for '%s' (draft flag %t)", c.expected, c.title, c.draftFlag)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package bitbadger
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DurationFormatter formats the durations shown in badge messages.
+type DurationFormatter interface {
+	FormatDuration(duration time.Duration, localizer Localizer) string
+}
+
+// Duration formats available by default.
+const (
+	// LongDurationFormat prints durations with words, such as "2 days 3 hours".
+	LongDurationFormat = "long"
+	// CompactDurationFormat prints durations with abbreviations, such as
+	// "2d 3h".
+	CompactDurationFormat = "compact"
+	// ISO8601DurationFormat prints ISO-8601 durations, such as "P2DT3H".
+	ISO8601DurationFormat = "iso8601"
+)
+
+var defaultDurationFormat = LongDurationFormat
+
+var durationFormatters = map[string]DurationFormatter{
+	LongDurationFormat:    longDurationFormatter{},
+	CompactDurationFormat: compactDurationFormatter{},
+	ISO8601DurationFormat: iso8601DurationFormatter{},
+}
+
+// RegisterDurationFormatter adds or replaces a duration format. It must be
+// called before serving badges.
+func RegisterDurationFormatter(name string, formatter DurationFormatter) {
+	durationFormatters[name] = formatter
+}
+
+// SetDefaultDurationFormat sets the duration format used by the package level
+// functions when the request doesn't select one. It returns an error if the
+// format doesn't exist.
+func SetDefaultDurationFormat(name string) error {
+	if _, found := durationFormatters[name]; !found {
+		return fmt.Errorf("Unsupported duration format '%s'", name)
+	}
+
+	defaultDurationFormat = name
+	return nil
+}
+
+// GetDefaultDurationFormat returns the duration format used when the request
+// doesn't select one.
+func GetDefaultDurationFormat() string {
+	return defaultDurationFormat
+}
+
+// durationPart is an amount of a duration unit.
+type durationPart struct {
+	unit   string
+	amount int64
+}
+
+// durationParts splits a duration in days, hours, minutes and seconds, and
+// returns the two most significant non-zero parts.
+func durationParts(duration time.Duration) []durationPart {
+	all := []durationPart{
+		{"day", int64(duration / (24 * time.Hour))},
+		{"hour", int64(duration % (24 * time.Hour) / time.Hour)},
+		{"min", int64(duration % time.Hour / time.Minute)},
+		{"sec", int64(duration % time.Minute / time.Second)},
+	}
+
+	parts := []durationPart{}
+	for _, part := range all {
+		if part.amount == 0 {
+			continue
+		}
+
+		parts = append(parts, part)
+		if len(parts) >= 2 {
+			break
+		}
+	}
+
+	return parts
+}
+
+type longDurationFormatter struct{}
+
+func (longDurationFormatter) FormatDuration(duration time.Duration, localizer Localizer) string {
+	words := []string{}
+	for _, part := range durationParts(duration) {
+		key := "unit." + part.unit + "s"
+		if part.amount == 1 {
+			key = "unit." + part.unit
+		}
+		words = append(words, fmt.Sprintf("%d %s", part.amount, localizer.Text(key)))
+	}
+
+	return strings.Join(words, " ")
+}
+
+type compactDurationFormatter struct{}
+
+func (compactDurationFormatter) FormatDuration(duration time.Duration, localizer Localizer) string {
+	words := []string{}
+	for _, part := range durationParts(duration) {
+		words = append(words, fmt.Sprintf("%d%s", part.amount, localizer.Text("unit."+part.unit+".short")))
+	}
+
+	if len(words) == 0 {
+		return "0" + localizer.Text("unit.sec.short")
+	}
+
+	return strings.Join(words, " ")
+}
+
+type iso8601DurationFormatter struct{}
+
+// FormatDuration prints an ISO-8601 duration, which is the same in all
+// languages.
+func (iso8601DurationFormatter) FormatDuration(duration time.Duration, localizer Localizer) string {
+	designators := map[string]string{"day": "D", "hour": "H", "min": "M", "sec": "S"}
+
+	date := ""
+	clock := ""
+	for _, part := range durationParts(duration) {
+		if part.unit == "day" {
+			date += fmt.Sprintf("%d%s", part.amount, designators[part.unit])
+		} else {
+			clock += fmt.Sprintf("%d%s", part.amount, designators[part.unit])
+		}
+	}
+
+	switch {
+	case date == "" && clock == "":
+		return "PT0S"
+	case clock == "":
+		return "P" + date
+	default:
+		return "P" + date + "T" + clock
+	}
+}
@@ -0,0 +1,55 @@
+package bitbadger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatDuration(t *testing.T) {
+	cases := []struct {
+		language string
+		format   string
+		duration time.Duration
+		expected string
+	}{
+		{"en", LongDurationFormat, 0, ""},
+		{"en", LongDurationFormat, 26*time.Hour + 5*time.Minute, "1 day 2 hours"},
+		{"en", LongDurationFormat, 3*time.Hour + 1*time.Minute + 10*time.Second, "3 hours 1 min"},
+		{"en", LongDurationFormat, 2*24*time.Hour + 30*time.Second, "2 days 30 secs"},
+		{"fr", LongDurationFormat, 49 * time.Hour, "2 jours 1 heure"},
+		{"en", CompactDurationFormat, 0, "0s"},
+		{"en", CompactDurationFormat, 51*time.Hour + 20*time.Minute, "2d 3h"},
+		{"fr", CompactDurationFormat, 75 * time.Minute, "1h 15min"},
+		{"en", ISO8601DurationFormat, 0, "PT0S"},
+		{"de", ISO8601DurationFormat, 48 * time.Hour, "P2D"},
+		{"en", ISO8601DurationFormat, 90 * time.Minute, "PT1H30M"},
+		{"en", ISO8601DurationFormat, 51*time.Hour + 20*time.Minute, "P2DT3H"},
+	}
+
+	for _, c := range cases {
+		localizer, err := NewLocalizer(c.language, c.format)
+		if err != nil {
+			t.Fatalf("NewLocalizer: Unexpected error: %s", err)
+		}
+
+		if duration := localizer.Duration(c.duration); duration != c.expected {
+			t.Errorf("Duration: Expected '%s' for %s in %s/%s, got '%s'", c.expected, c.duration, c.language, c.format, duration)
+		}
+	}
+}
+
+func TestSetDefaultDurationFormat(t *testing.T) {
+	defer SetDefaultDurationFormat(LongDurationFormat)
+
+	if SetDefaultDurationFormat("fancy") == nil {
+		t.Errorf("SetDefaultDurationFormat: Should generate an error for unknown formats")
+	}
+
+	SetDefaultDurationFormat(CompactDurationFormat)
+	if GetDefaultDurationFormat() != CompactDurationFormat {
+		t.Errorf("Set/GetDefaultDurationFormat: Formats differ")
+	}
+	if DefaultLocalizer().Duration(2*time.Hour) != "2h" {
+		t.Errorf("DefaultLocalizer: Should use the default duration format")
+	}
+}
@@ -0,0 +1,252 @@
+package bitbadger
+
+import (
+	"errors"
+	"io/ioutil"
+	"math"
+	"sort"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// HealthCriterion holds how a metric contributes to the health score. The
+// metric scores 100 when it reaches Target, 0 when it reaches Limit, and is
+// interpolated linearly in between. Durations are expressed in hours.
+type HealthCriterion struct {
+	Weight float64 `yaml:"weight"`
+	Target float64 `yaml:"target"`
+	Limit  float64 `yaml:"limit"`
+}
+
+// HealthPolicy holds the criteria used to compute the health score, indexed
+// by metric name. Metric names are the names of the corresponding badges.
+type HealthPolicy struct {
+	// Grade shows the score as a A to F grade instead of a 0 to 100 score.
+	Grade    bool                       `yaml:"grade"`
+	Criteria map[string]HealthCriterion `yaml:"criteria"`
+}
+
+// HealthCriterionScore holds the score of a single health criterion.
+type HealthCriterionScore struct {
+	Metric string
+	Value  float64
+	HealthCriterion
+	Score float64
+}
+
+// HealthInfo holds the health score and how it was derived.
+type HealthInfo struct {
+	Score    float64
+	Grade    string
+	UseGrade bool
+	Criteria []HealthCriterionScore
+}
+
+// healthMetric gets the value of a health metric, converting durations to
+// hours.
+type healthMetric struct {
+	value func(prInfo PullRequestsInfo) float64
+	// sampled returns false if there is no pull request to measure the
+	// metric, such as the average reviewers of a repository without open
+	// PRs. A nil function means the metric is always measured.
+	sampled func(prInfo PullRequestsInfo) bool
+}
+
+func hasOpenPRs(prInfo PullRequestsInfo) bool {
+	return prInfo.OpenCount > 0
+}
+
+func hasMergeTimeSamples(prInfo PullRequestsInfo) bool {
+	return prInfo.MergeTimeSamples > 0
+}
+
+func hasPRs(prInfo PullRequestsInfo) bool {
+	return prInfo.OpenCount > 0 || prInfo.MergedCount > 0 || prInfo.MergeTimeSamples > 0
+}
+
+// healthMetrics lists the metrics which can be used as health criteria, and
+// how to get their value.
+var healthMetrics = map[string]healthMetric{
+	string(OpenPRCountType): {value: func(prInfo PullRequestsInfo) float64 {
+		return float64(prInfo.OpenCount)
+	}},
+	string(OldestOpenPRAge): {value: func(prInfo PullRequestsInfo) float64 {
+		return prInfo.OldestOpenPR.Hours()
+	}, sampled: hasOpenPRs},
+	string(OpenPRAverageAgeType): {value: func(prInfo PullRequestsInfo) float64 {
+		return prInfo.OpenAverageTime.Hours()
+	}, sampled: hasOpenPRs},
+	string(AveragePRMergeTime): {value: func(prInfo PullRequestsInfo) float64 {
+		return prInfo.AveragePRMergeTime.Hours()
+	}, sampled: hasMergeTimeSamples},
+	string(AwaitingReviewCountType): {value: func(prInfo PullRequestsInfo) float64 {
+		return float64(prInfo.AwaitingReviewCount)
+	}},
+	string(AverageReviewersType): {value: func(prInfo PullRequestsInfo) float64 {
+		return prInfo.AverageReviewers
+	}, sampled: hasOpenPRs},
+	string(MergedPRsPerWeekType): {value: func(prInfo PullRequestsInfo) float64 {
+		return throughputRate(prInfo.MergedCount, prInfo.MergedWindow, 7*24*time.Hour)
+	}, sampled: hasPRs},
+}
+
+// DefaultHealthPolicy returns the health policy used when none is configured.
+func DefaultHealthPolicy() HealthPolicy {
+	return HealthPolicy{
+		Criteria: map[string]HealthCriterion{
+			string(OpenPRCountType):         {Weight: 1, Target: 3, Limit: 15},
+			string(OldestOpenPRAge):         {Weight: 1, Target: 24, Limit: 240},
+			string(OpenPRAverageAgeType):    {Weight: 1, Target: 24, Limit: 120},
+			string(AveragePRMergeTime):      {Weight: 1, Target: 24, Limit: 120},
+			string(AwaitingReviewCountType): {Weight: 0.5, Target: 0, Limit: 10},
+			string(AverageReviewersType):    {Weight: 0.5, Target: 2, Limit: 0},
+			string(MergedPRsPerWeekType):    {Weight: 0.5, Target: 10, Limit: 0},
+		},
+	}
+}
+
+var healthPolicy = DefaultHealthPolicy()
+
+// SetHealthPolicy sets the global health policy. It returns an error if the
+// policy is not valid, in which case the current policy is left untouched.
+func SetHealthPolicy(policy HealthPolicy) error {
+	err := policy.Validate()
+	if err != nil {
+		return err
+	}
+
+	healthPolicy = policy
+	return nil
+}
+
+// GetHealthPolicy returns the current global health policy.
+func GetHealthPolicy() HealthPolicy {
+	return healthPolicy
+}
+
+// LoadHealthPolicy loads a health policy from a YAML file. Criteria which are
+// not in the file keep their default values.
+func LoadHealthPolicy(path string) (HealthPolicy, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return HealthPolicy{}, err
+	}
+
+	return parseHealthPolicy(data)
+}
+
+func parseHealthPolicy(data []byte) (HealthPolicy, error) {
+	var filePolicy HealthPolicy
+	err := yaml.UnmarshalStrict(data, &filePolicy)
+	if err != nil {
+		return HealthPolicy{}, err
+	}
+
+	policy := DefaultHealthPolicy()
+	policy.Grade = filePolicy.Grade
+	for metric, criterion := range filePolicy.Criteria {
+		policy.Criteria[metric] = criterion
+	}
+
+	err = policy.Validate()
+	if err != nil {
+		return HealthPolicy{}, err
+	}
+
+	return policy, nil
+}
+
+// Validate returns an error if a criterion has an unknown metric, a negative
+// weight, or the same target and limit, or if no criterion has a weight.
+func (policy HealthPolicy) Validate() error {
+	totalWeight := float64(0)
+	for metric, criterion := range policy.Criteria {
+		if _, valid := healthMetrics[metric]; !valid {
+			return errors.New("Invalid health metric '" + metric + "'")
+		}
+		if criterion.Weight < 0 {
+			return errors.New("Health metric '" + metric + "' has a negative weight")
+		}
+		if criterion.Weight > 0 && criterion.Target == criterion.Limit {
+			return errors.New("Health metric '" + metric + "' has the same target and limit")
+		}
+
+		totalWeight += criterion.Weight
+	}
+
+	if totalWeight == 0 {
+		return errors.New("Health policy requires at least one metric with a positive weight")
+	}
+
+	return nil
+}
+
+// computeHealth computes the health score from pull request information.
+func computeHealth(prInfo PullRequestsInfo, policy HealthPolicy) HealthInfo {
+	metrics := make([]string, 0, len(policy.Criteria))
+	for metric := range policy.Criteria {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+
+	healthInfo := HealthInfo{
+		UseGrade: policy.Grade,
+		Criteria: []HealthCriterionScore{},
+	}
+
+	weightedScores := float64(0)
+	totalWeight := float64(0)
+	for _, metric := range metrics {
+		criterion := policy.Criteria[metric]
+		if criterion.Weight == 0 {
+			continue
+		}
+
+		// Metrics without samples are left out, the others are re-weighted.
+		if sampled := healthMetrics[metric].sampled; sampled != nil && !sampled(prInfo) {
+			continue
+		}
+
+		value := healthMetrics[metric].value(prInfo)
+		score := criterionScore(value, criterion)
+
+		healthInfo.Criteria = append(healthInfo.Criteria, HealthCriterionScore{
+			Metric:          metric,
+			Value:           value,
+			HealthCriterion: criterion,
+			Score:           score,
+		})
+
+		weightedScores += criterion.Weight * score
+		totalWeight += criterion.Weight
+	}
+
+	if totalWeight > 0 {
+		healthInfo.Score = math.Round(weightedScores / totalWeight)
+	}
+	healthInfo.Grade = healthGrade(healthInfo.Score)
+
+	return healthInfo
+}
+
+// criterionScore returns the 0 to 100 score of value for criterion.
+func criterionScore(value float64, criterion HealthCriterion) float64 {
+	score := 100 * (value - criterion.Limit) / (criterion.Target - criterion.Limit)
+	return math.Max(0, math.Min(100, score))
+}
+
+func healthGrade(score float64) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 80:
+		return "B"
+	case score >= 70:
+		return "C"
+	case score >= 60:
+		return "D"
+	default:
+		return "F"
+	}
+}
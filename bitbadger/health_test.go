@@ -0,0 +1,151 @@
+package bitbadger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeHealth(t *testing.T) {
+	policy := HealthPolicy{
+		Criteria: map[string]HealthCriterion{
+			string(OpenPRCountType):      {Weight: 1, Target: 2, Limit: 10},
+			string(OldestOpenPRAge):      {Weight: 3, Target: 24, Limit: 120},
+			string(AverageReviewersType): {Weight: 0, Target: 2, Limit: 0},
+		},
+	}
+
+	healthInfo := computeHealth(PullRequestsInfo{
+		OpenCount:    6,
+		OldestOpenPR: 12 * time.Hour,
+	}, policy)
+
+	// Open PR count scores 50, oldest PR age scores 100 with 3 times the
+	// weight, and average reviewers is ignored.
+	if healthInfo.Score != 88 {
+		t.Errorf("computeHealth: Expected score 88, got %f", healthInfo.Score)
+	}
+	if healthInfo.Grade != "B" {
+		t.Errorf("computeHealth: Expected grade B, got %s", healthInfo.Grade)
+	}
+	if len(healthInfo.Criteria) != 2 {
+		t.Fatalf("computeHealth: Expected 2 criteria, got %d", len(healthInfo.Criteria))
+	}
+	if healthInfo.Criteria[0].Metric != string(OldestOpenPRAge) || healthInfo.Criteria[0].Score != 100 {
+		t.Errorf("computeHealth: Unexpected criterion %+v", healthInfo.Criteria[0])
+	}
+	if healthInfo.Criteria[1].Metric != string(OpenPRCountType) || healthInfo.Criteria[1].Score != 50 {
+		t.Errorf("computeHealth: Unexpected criterion %+v", healthInfo.Criteria[1])
+	}
+}
+
+func TestComputeHealthWithoutSamples(t *testing.T) {
+	// Without any PR, only the counts are measured, and score 100.
+	healthInfo := computeHealth(PullRequestsInfo{}, DefaultHealthPolicy())
+	if healthInfo.Score != 100 {
+		t.Errorf("computeHealth: Expected score 100, got %f", healthInfo.Score)
+	}
+	if len(healthInfo.Criteria) != 2 {
+		t.Errorf("computeHealth: Expected 2 criteria, got %+v", healthInfo.Criteria)
+	}
+
+	// Without open PR, the merge time and throughput are still measured.
+	healthInfo = computeHealth(PullRequestsInfo{
+		AveragePRMergeTime: 24 * time.Hour,
+		MergeTimeSamples:   4,
+		MergedCount:        40,
+		MergedWindow:       28 * 24 * time.Hour,
+	}, DefaultHealthPolicy())
+	if healthInfo.Score != 100 || len(healthInfo.Criteria) != 4 {
+		t.Errorf("computeHealth: Expected score 100 from 4 criteria, got %+v", healthInfo)
+	}
+}
+
+func TestCriterionScore(t *testing.T) {
+	lowerIsBetter := HealthCriterion{Weight: 1, Target: 10, Limit: 20}
+	higherIsBetter := HealthCriterion{Weight: 1, Target: 2, Limit: 0}
+
+	cases := []struct {
+		value     float64
+		criterion HealthCriterion
+		expected  float64
+	}{
+		{5, lowerIsBetter, 100},
+		{15, lowerIsBetter, 50},
+		{25, lowerIsBetter, 0},
+		{3, higherIsBetter, 100},
+		{0.5, higherIsBetter, 25},
+		{0, higherIsBetter, 0},
+	}
+
+	for _, c := range cases {
+		if score := criterionScore(c.value, c.criterion); score != c.expected {
+			t.Errorf("criterionScore: Expected %f for %f, got %f", c.expected, c.value, score)
+		}
+	}
+}
+
+func TestParseHealthPolicy(t *testing.T) {
+	policy, err := parseHealthPolicy([]byte(`
+grade: true
+criteria:
+  open-pr-count:
+    weight: 2
+    target: 1
+    limit: 5
+  merged-prs-per-week:
+    weight: 0
+`))
+	if err != nil {
+		t.Fatalf("parseHealthPolicy: Unexpected error: %s", err)
+	}
+
+	if !policy.Grade {
+		t.Errorf("parseHealthPolicy: Grade should be enabled")
+	}
+	if policy.Criteria[string(OpenPRCountType)] != (HealthCriterion{Weight: 2, Target: 1, Limit: 5}) {
+		t.Errorf("parseHealthPolicy: Open PR count criterion not loaded")
+	}
+	if policy.Criteria[string(MergedPRsPerWeekType)].Weight != 0 {
+		t.Errorf("parseHealthPolicy: Merged PRs per week should be disabled")
+	}
+	if policy.Criteria[string(OldestOpenPRAge)] != DefaultHealthPolicy().Criteria[string(OldestOpenPRAge)] {
+		t.Errorf("parseHealthPolicy: Missing criteria should keep their default values")
+	}
+
+	invalidPolicies := []string{
+		"criteria:\n  unknown-metric:\n    weight: 1\n",
+		"criteria:\n  open-pr-count:\n    weight: -1\n",
+		"criteria:\n  open-pr-count:\n    weight: 1\n    target: 3\n    limit: 3\n",
+		"unknown-field: true\n",
+	}
+
+	for _, invalidPolicy := range invalidPolicies {
+		_, err := parseHealthPolicy([]byte(invalidPolicy))
+		if err == nil {
+			t.Errorf("parseHealthPolicy: Should generate an error for:\n%s", invalidPolicy)
+		}
+	}
+}
+
+func TestSetHealthPolicy(t *testing.T) {
+	defer SetHealthPolicy(DefaultHealthPolicy())
+
+	err := SetHealthPolicy(HealthPolicy{Criteria: map[string]HealthCriterion{}})
+	if err == nil {
+		t.Errorf("SetHealthPolicy: Should generate an error without criteria")
+	}
+
+	newPolicy := HealthPolicy{
+		Grade: true,
+		Criteria: map[string]HealthCriterion{
+			string(OpenPRCountType): {Weight: 1, Target: 0, Limit: 10},
+		},
+	}
+	err = SetHealthPolicy(newPolicy)
+	if err != nil {
+		t.Errorf("SetHealthPolicy: Unexpected error: %s", err)
+	}
+	if !GetHealthPolicy().Grade || len(GetHealthPolicy().Criteria) != 1 {
+		t.Errorf("Set/GetHealthPolicy: Health policies differ")
+	}
+}
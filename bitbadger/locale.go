@@ -0,0 +1,180 @@
+package bitbadger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Catalog holds the badge strings of a language, indexed by message key.
+// Keys missing from a catalog fall back to the English catalog.
+type Catalog map[string]string
+
+// DefaultLanguage is the language of badges, unless the request selects
+// another one.
+const DefaultLanguage = "en"
+
+var catalogs = map[string]Catalog{
+	"en": englishCatalog,
+	"fr": frenchCatalog,
+	"de": germanCatalog,
+	"es": spanishCatalog,
+}
+
+// RegisterCatalog adds or replaces the catalog of a language. It must be
+// called before serving badges.
+func RegisterCatalog(language string, catalog Catalog) {
+	catalogs[strings.ToLower(language)] = catalog
+}
+
+// Languages returns the languages with a catalog, sorted alphabetically.
+func Languages() []string {
+	languages := make([]string, 0, len(catalogs))
+	for language := range catalogs {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	return languages
+}
+
+// Localizer formats the labels and messages of badges in a language, using a
+// duration format.
+type Localizer struct {
+	catalog  Catalog
+	duration DurationFormatter
+}
+
+// NewLocalizer returns a localizer for a language and a duration format. Empty
+// values select the default language and format.
+func NewLocalizer(language string, durationFormat string) (Localizer, error) {
+	if language == "" {
+		language = DefaultLanguage
+	}
+	catalog, found := catalogs[strings.ToLower(language)]
+	if !found {
+		return Localizer{}, errors.New("Unsupported language '" + language +
+			"', expected one of " + strings.Join(Languages(), ", "))
+	}
+
+	if durationFormat == "" {
+		durationFormat = defaultDurationFormat
+	}
+	formatter, found := durationFormatters[durationFormat]
+	if !found {
+		return Localizer{}, errors.New("Unsupported duration format '" + durationFormat + "'")
+	}
+
+	return Localizer{
+		catalog:  catalog,
+		duration: formatter,
+	}, nil
+}
+
+// DefaultLocalizer returns the localizer of the default language and duration
+// format.
+func DefaultLocalizer() Localizer {
+	localizer, err := NewLocalizer("", "")
+	if err != nil {
+		panic(err)
+	}
+
+	return localizer
+}
+
+// requestLocalizer returns the localizer selected by a request, using the
+// duration format of the server unless the request selects one. Requests are
+// validated when parsed, so errors fall back to the default localizer.
+func (server *Server) requestLocalizer(request BadgeRequest) Localizer {
+	durationFormat := request.DurationFormat
+	if durationFormat == "" {
+		durationFormat = server.durationFormat
+	}
+
+	localizer, err := NewLocalizer(request.Language, durationFormat)
+	if err != nil {
+		return DefaultLocalizer()
+	}
+
+	return localizer
+}
+
+// Text returns the string of a message key, formatted with args if any.
+func (localizer Localizer) Text(key string, args ...interface{}) string {
+	text, found := localizer.catalog[key]
+	if !found {
+		text, found = englishCatalog[key]
+	}
+	if !found {
+		text = key
+	}
+
+	if len(args) == 0 {
+		return text
+	}
+
+	return fmt.Sprintf(text, args...)
+}
+
+// Duration formats a duration with the localizer duration format.
+func (localizer Localizer) Duration(duration time.Duration) string {
+	return localizer.duration.FormatDuration(duration, localizer)
+}
+
+// Number formats a number with a fixed number of decimals, using the decimal
+// separator of the language.
+func (localizer Localizer) Number(number float64, decimals int) string {
+	text := strconv.FormatFloat(number, 'f', decimals, 64)
+	return strings.Replace(text, ".", localizer.Text("number.decimal"), 1)
+}
+
+// negotiateLanguage returns the first language of an Accept-Language header
+// which has a catalog, ordered by preference, or an empty string if none has.
+func negotiateLanguage(acceptLanguage string) string {
+	type weightedLanguage struct {
+		language string
+		weight   float64
+	}
+
+	languages := []weightedLanguage{}
+	for _, entry := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(entry), ";")
+		language := weightedLanguage{
+			language: strings.ToLower(strings.TrimSpace(fields[0])),
+			weight:   1,
+		}
+
+		for _, field := range fields[1:] {
+			field = strings.TrimSpace(field)
+			if strings.HasPrefix(field, "q=") {
+				weight, err := strconv.ParseFloat(field[2:], 64)
+				if err == nil {
+					language.weight = weight
+				}
+			}
+		}
+
+		languages = append(languages, language)
+	}
+
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].weight > languages[j].weight
+	})
+
+	for _, language := range languages {
+		if language.weight <= 0 {
+			continue
+		}
+
+		// Regional variants such as "fr-CH" use the catalog of their language.
+		base := strings.SplitN(language.language, "-", 2)[0]
+		if _, found := catalogs[base]; found {
+			return base
+		}
+	}
+
+	return ""
+}
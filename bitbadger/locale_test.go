@@ -0,0 +1,138 @@
+package bitbadger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewLocalizer(t *testing.T) {
+	localizer, err := NewLocalizer("DE", CompactDurationFormat)
+	if err != nil {
+		t.Fatalf("NewLocalizer: Unexpected error: %s", err)
+	}
+	if localizer.Text("label.open-prs") != "Offene PRs" {
+		t.Errorf("NewLocalizer: Should select the German catalog")
+	}
+
+	_, err = NewLocalizer("xx", "")
+	if err == nil {
+		t.Errorf("NewLocalizer: Should generate an error for unknown languages")
+	}
+	_, err = NewLocalizer("", "fancy")
+	if err == nil {
+		t.Errorf("NewLocalizer: Should generate an error for unknown duration formats")
+	}
+}
+
+func TestLocalizerText(t *testing.T) {
+	RegisterCatalog("xx", Catalog{"label.open-prs": "Xx PRs"})
+	defer delete(catalogs, "xx")
+
+	localizer, _ := NewLocalizer("xx", "")
+	if localizer.Text("label.open-prs") != "Xx PRs" {
+		t.Errorf("Text: Should use the registered catalog")
+	}
+	if localizer.Text("label.draft-prs") != "Draft PRs" {
+		t.Errorf("Text: Should fall back to the English catalog")
+	}
+	if localizer.Text("unknown.key") != "unknown.key" {
+		t.Errorf("Text: Should fall back to the key")
+	}
+	if localizer.Text("message.ago", "2 days") != "2 days ago" {
+		t.Errorf("Text: Should format arguments")
+	}
+}
+
+func TestLocalizerNumber(t *testing.T) {
+	english := DefaultLocalizer()
+	french, _ := NewLocalizer("fr", "")
+
+	if english.Number(1.25, 1) != "1.2" || french.Number(1.5, 1) != "1,5" || french.Number(12, 0) != "12" {
+		t.Errorf("Number: Unexpected numbers %s, %s, %s", english.Number(1.25, 1), french.Number(1.5, 1), french.Number(12, 0))
+	}
+}
+
+func TestLocalizedBadges(t *testing.T) {
+	prInfo := PullRequestsInfo{
+		OpenCount:        3,
+		OldestOpenPR:     50 * time.Hour,
+		AverageReviewers: 1.5,
+		MergedCount:      10,
+		MergedWindow:     4 * 7 * 24 * time.Hour,
+	}
+
+	cases := []struct {
+		language        string
+		durationFormat  string
+		badgeType       BadgeType
+		expectedLabel   string
+		expectedMessage string
+	}{
+		{"fr", "", OldestOpenPRAge, "Âge de la plus vieille PR", "2 jours 2 heures"},
+		{"de", CompactDurationFormat, OldestOpenPRAge, "Alter der ältesten PR", "2T 2Std"},
+		{"es", ISO8601DurationFormat, OldestOpenPRAge, "PR más antigua", "P2DT2H"},
+		{"fr", "", AverageReviewersType, "Relecteurs moy. par PR", "1,5"},
+		{"de", "", MergedPRsPerWeekType, "Gemergte PRs", "2,5/Woche"},
+	}
+
+	for _, c := range cases {
+		localizer, err := NewLocalizer(c.language, c.durationFormat)
+		if err != nil {
+			t.Fatalf("NewLocalizer: Unexpected error: %s", err)
+		}
+
+		badgeInfo, _ := GenerateLocalizedBadgeInfo(c.badgeType, prInfo, localizer)
+		if badgeInfo.Label != c.expectedLabel || badgeInfo.Message != c.expectedMessage {
+			t.Errorf("GenerateLocalizedBadgeInfo: Expected '%s: %s' in %s, got '%s: %s'",
+				c.expectedLabel, c.expectedMessage, c.language, badgeInfo.Label, badgeInfo.Message)
+		}
+	}
+
+	spanish, _ := NewLocalizer("es", "")
+	badgeInfo, _ := GenerateCommitsBadgeInfo(LastCommitType, CommitsInfo{LastCommitAge: 3 * time.Hour}, spanish)
+	if badgeInfo.Message != "hace 3 horas" {
+		t.Errorf("GenerateCommitsBadgeInfo: Unexpected message '%s'", badgeInfo.Message)
+	}
+}
+
+func TestCatalogsComplete(t *testing.T) {
+	for language, catalog := range catalogs {
+		for key := range englishCatalog {
+			if _, found := catalog[key]; !found {
+				t.Errorf("Catalog '%s' is missing '%s'", language, key)
+			}
+		}
+	}
+}
+
+func TestLocalizedBadgeURLs(t *testing.T) {
+	for _, language := range Languages() {
+		localizer, _ := NewLocalizer(language, "")
+		for _, message := range []string{printRate(2.5, "week", localizer), localizer.Text("message.not-available")} {
+			_, urlMessage, _ := parseBadgeURL(t, generateBadgeURL(BadgeInfo{Label: "label", Message: message, Color: "blue"}))
+			if urlMessage != message {
+				t.Errorf("generateBadgeURL: Expected message '%s' in '%s', got '%s'", message, language, urlMessage)
+			}
+		}
+	}
+}
+
+func TestNegotiateLanguage(t *testing.T) {
+	cases := []struct {
+		acceptLanguage string
+		expected       string
+	}{
+		{"", ""},
+		{"*", ""},
+		{"fr-FR,fr;q=0.9,en;q=0.8", "fr"},
+		{"en;q=0.5, es", "es"},
+		{"de;q=0, en", "en"},
+		{"pt-BR", ""},
+	}
+
+	for _, c := range cases {
+		if language := negotiateLanguage(c.acceptLanguage); language != c.expected {
+			t.Errorf("negotiateLanguage: Expected '%s' for '%s', got '%s'", c.expected, c.acceptLanguage, language)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package bitbadger
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetricsPath is the path of the Prometheus metrics.
+const MetricsPath = "/metrics"
+
+// durationBuckets are the upper bounds of the duration histograms, in seconds.
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Metrics shared by all servers of the process.
+var (
+	requestsTotal = newCounterVec("bitbadger_requests_total",
+		"Badge requests served, by badge type and HTTP status.", "type", "status")
+	requestDuration = newHistogramVec("bitbadger_request_duration_seconds",
+		"Duration of badge requests, by badge type and HTTP status.", "type", "status")
+	upstreamRequestsTotal = newCounterVec("bitbadger_upstream_requests_total",
+		"Requests sent to upstream repositories, by provider and endpoint.", "provider", "endpoint")
+	upstreamErrorsTotal = newCounterVec("bitbadger_upstream_errors_total",
+		"Failed requests to upstream repositories, by provider, endpoint and error kind.", "provider", "endpoint", "kind")
+	upstreamRequestDuration = newHistogramVec("bitbadger_upstream_request_duration_seconds",
+		"Duration of requests to upstream repositories, by provider and endpoint.", "provider", "endpoint")
+	renderDuration = newHistogramVec("bitbadger_render_duration_seconds",
+		"Duration of badge image rendering.")
+	workspaceSkippedTotal = newCounterVec("bitbadger_workspace_skipped_repositories_total",
+		"Repositories left out of workspace badges, by error kind.", "kind")
+)
+
+// observeUpstreamRequest records a request to an upstream repository.
+func observeUpstreamRequest(provider string, endpoint string, duration time.Duration, err error) {
+	upstreamRequestsTotal.add(1, provider, endpoint)
+	upstreamRequestDuration.observe(duration.Seconds(), provider, endpoint)
+
+	if err == nil {
+		return
+	}
+	upstreamErrorsTotal.add(1, provider, endpoint, errorKindLabel(err))
+}
+
+// errorKindLabel returns the kind of an upstream error as a metric label.
+func errorKindLabel(err error) string {
+	if upstreamErr, ok := err.(*UpstreamError); ok {
+		return strings.Replace(upstreamErr.Kind.String(), " ", "_", -1)
+	}
+
+	return "network"
+}
+
+// serveMetrics sends the metrics in the Prometheus text format.
+func (server *Server) serveMetrics(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	server.writeMetrics(w)
+}
+
+// writeMetrics writes the process metrics, and the metrics of the server
+// cache.
+func (server *Server) writeMetrics(w io.Writer) {
+	requestsTotal.write(w)
+	requestDuration.write(w)
+	upstreamRequestsTotal.write(w)
+	upstreamErrorsTotal.write(w)
+	upstreamRequestDuration.write(w)
+	renderDuration.write(w)
+	workspaceSkippedTotal.write(w)
+
+	stats := server.cache.Stats()
+	writeMetric(w, "bitbadger_cache_hits_total", "counter", "Cache lookups finding a valid result.", stats.Hits)
+	writeMetric(w, "bitbadger_cache_misses_total", "counter", "Cache lookups finding no valid result.", stats.Misses)
+	writeMetric(w, "bitbadger_cache_evictions_total", "counter", "Cached results evicted to stay under the maximum size.", stats.Evictions)
+	writeMetric(w, "bitbadger_cache_size", "gauge", "Number of cached results.", stats.Size)
+}
+
+// writeMetric writes a metric without labels.
+func writeMetric(w io.Writer, name string, metricType string, help string, value int) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %d\n", name, help, name, metricType, name, value)
+}
+
+// metricSeries identifies a series of a metric by its label values.
+type metricSeries struct {
+	key    string
+	values []string
+}
+
+// seriesKey returns the key of the series with labelValues.
+func seriesKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
+
+// formatLabels formats label pairs, with extra pairs appended, such as
+// `{type="build-status",status="200"}`.
+func formatLabels(names []string, values []string, extra ...string) string {
+	pairs := []string{}
+	for i, name := range names {
+		pairs = append(pairs, name+"="+strconv.Quote(values[i]))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, extra[i]+"="+strconv.Quote(extra[i+1]))
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// formatValue formats a metric value.
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// counterVec is a counter split in series by label values. It is safe for
+// concurrent use.
+type counterVec struct {
+	mutex  sync.Mutex
+	name   string
+	help   string
+	labels []string
+	series map[string]metricSeries
+	values map[string]float64
+}
+
+func newCounterVec(name string, help string, labels ...string) *counterVec {
+	return &counterVec{
+		name:   name,
+		help:   help,
+		labels: labels,
+		series: map[string]metricSeries{},
+		values: map[string]float64{},
+	}
+}
+
+// add adds value to the series with labelValues.
+func (counter *counterVec) add(value float64, labelValues ...string) {
+	counter.mutex.Lock()
+	defer counter.mutex.Unlock()
+
+	key := seriesKey(labelValues)
+	if _, found := counter.series[key]; !found {
+		counter.series[key] = metricSeries{key, labelValues}
+	}
+	counter.values[key] += value
+}
+
+func (counter *counterVec) write(w io.Writer) {
+	counter.mutex.Lock()
+	defer counter.mutex.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", counter.name, counter.help, counter.name)
+	for _, series := range sortedSeries(counter.series) {
+		fmt.Fprintf(w, "%s%s %s\n", counter.name, formatLabels(counter.labels, series.values), formatValue(counter.values[series.key]))
+	}
+}
+
+// histogram holds the observations of a series.
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// histogramVec is a histogram of durations split in series by label values.
+// It is safe for concurrent use.
+type histogramVec struct {
+	mutex      sync.Mutex
+	name       string
+	help       string
+	labels     []string
+	series     map[string]metricSeries
+	histograms map[string]*histogram
+}
+
+func newHistogramVec(name string, help string, labels ...string) *histogramVec {
+	return &histogramVec{
+		name:       name,
+		help:       help,
+		labels:     labels,
+		series:     map[string]metricSeries{},
+		histograms: map[string]*histogram{},
+	}
+}
+
+// observe adds an observation to the series with labelValues.
+func (vec *histogramVec) observe(value float64, labelValues ...string) {
+	vec.mutex.Lock()
+	defer vec.mutex.Unlock()
+
+	key := seriesKey(labelValues)
+	h, found := vec.histograms[key]
+	if !found {
+		h = &histogram{counts: make([]uint64, len(durationBuckets))}
+		vec.series[key] = metricSeries{key, labelValues}
+		vec.histograms[key] = h
+	}
+
+	for i, bound := range durationBuckets {
+		if value <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += value
+	h.count++
+}
+
+func (vec *histogramVec) write(w io.Writer) {
+	vec.mutex.Lock()
+	defer vec.mutex.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", vec.name, vec.help, vec.name)
+	for _, series := range sortedSeries(vec.series) {
+		h := vec.histograms[series.key]
+		for i, bound := range durationBuckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", vec.name, formatLabels(vec.labels, series.values, "le", formatValue(bound)), h.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", vec.name, formatLabels(vec.labels, series.values, "le", "+Inf"), h.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", vec.name, formatLabels(vec.labels, series.values), formatValue(h.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", vec.name, formatLabels(vec.labels, series.values), h.count)
+	}
+}
+
+// sortedSeries returns series sorted by label values, for a stable output.
+func sortedSeries(series map[string]metricSeries) []metricSeries {
+	sorted := make([]metricSeries, 0, len(series))
+	for _, s := range series {
+		sorted = append(sorted, s)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].key < sorted[j].key
+	})
+
+	return sorted
+}
+
+// statusRecorder records the status of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (recorder *statusRecorder) WriteHeader(status int) {
+	recorder.status = status
+	recorder.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,84 @@
+package bitbadger
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCounterVecWrite(t *testing.T) {
+	counter := newCounterVec("test_total", "Test counter.", "type", "status")
+	counter.add(1, "build-status", "200")
+	counter.add(2, "build-status", "200")
+	counter.add(1, "bus-factor", "502")
+
+	var buffer bytes.Buffer
+	counter.write(&buffer)
+
+	expected := `# HELP test_total Test counter.
+# TYPE test_total counter
+test_total{type="build-status",status="200"} 3
+test_total{type="bus-factor",status="502"} 1
+`
+	if buffer.String() != expected {
+		t.Errorf("write: Expected:\n%s\ngot:\n%s", expected, buffer.String())
+	}
+}
+
+func TestHistogramVecWrite(t *testing.T) {
+	histogram := newHistogramVec("test_seconds", "Test histogram.", "endpoint")
+	histogram.observe(0.02, "commits")
+	histogram.observe(3, "commits")
+	histogram.observe(60, "commits")
+
+	var buffer bytes.Buffer
+	histogram.write(&buffer)
+
+	expectedLines := []string{
+		`# TYPE test_seconds histogram`,
+		`test_seconds_bucket{endpoint="commits",le="0.01"} 0`,
+		`test_seconds_bucket{endpoint="commits",le="0.025"} 1`,
+		`test_seconds_bucket{endpoint="commits",le="5"} 2`,
+		`test_seconds_bucket{endpoint="commits",le="30"} 2`,
+		`test_seconds_bucket{endpoint="commits",le="+Inf"} 3`,
+		`test_seconds_sum{endpoint="commits"} 63.02`,
+		`test_seconds_count{endpoint="commits"} 3`,
+	}
+	for _, line := range expectedLines {
+		if !strings.Contains(buffer.String(), line+"\n") {
+			t.Errorf("write: Expected line '%s' in:\n%s", line, buffer.String())
+		}
+	}
+}
+
+func TestServerMetrics(t *testing.T) {
+	server := NewServer(WithProvider(&fakeProvider{openCount: 3}), WithRenderer(LocalRenderer{}))
+
+	for _, url := range []string{"/user/repo/open-pr-count", "/user/repo/open-pr-count", "/user/repo/build-status"} {
+		server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", url, nil))
+	}
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest("GET", MetricsPath, nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("ServeHTTP: Expected status %d for the metrics, got %d", http.StatusOK, recorder.Code)
+	}
+
+	// Request metrics are shared by all servers of the process.
+	expectedContent := []string{
+		`bitbadger_requests_total{type="open-pr-count",status="200"} `,
+		`bitbadger_requests_total{type="build-status",status="502"} `,
+		`bitbadger_request_duration_seconds_count{type="open-pr-count",status="200"} `,
+		"bitbadger_render_duration_seconds_count ",
+		"bitbadger_cache_hits_total 1\n",
+		"bitbadger_cache_misses_total 2\n",
+		"bitbadger_cache_size 1\n",
+	}
+	for _, content := range expectedContent {
+		if !strings.Contains(recorder.Body.String(), content) {
+			t.Errorf("ServeHTTP: Expected '%s' in the metrics:\n%s", content, recorder.Body.String())
+		}
+	}
+}
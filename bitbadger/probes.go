@@ -0,0 +1,207 @@
+package bitbadger
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Paths of the liveness and readiness probes. Badge paths have at least three
+// elements, so they can't collide with a badge request.
+const (
+	HealthPath = "/healthz"
+	ReadyPath  = "/readyz"
+)
+
+// readyCheckInterval is how long the result of a readiness check is reused,
+// to spare the API quota of the upstream repository.
+var readyCheckInterval = time.Minute
+
+// Checker is implemented by the providers, renderers and caches which can
+// check that they are able to serve requests.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// workspaceChecker is implemented by the providers which can check that
+// they are able to serve the badges of a workspace.
+type workspaceChecker interface {
+	CheckWorkspace(ctx context.Context, workspace string) error
+}
+
+// checkerFunc is a Checker calling a function.
+type checkerFunc func(ctx context.Context) error
+
+func (check checkerFunc) Check(ctx context.Context) error {
+	return check(ctx)
+}
+
+// Check checks that the credentials are valid, by retrieving the account of
+// the user. Access tokens and OAuth consumers may lack the account scope, so
+// a 403 response, which BitBucket only sends to authenticated requests, is
+// not an error.
+func (bb *BBCloud) Check(ctx context.Context) error {
+	userURL := strings.TrimSuffix(bb.apiURL, "repositories/") + "user"
+	_, err := bb.queryBBURLOnce(ctx, userURL)
+	if upstreamErr, ok := err.(*UpstreamError); ok && upstreamErr.StatusCode == http.StatusForbidden {
+		return nil
+	}
+
+	return err
+}
+
+// CheckWorkspace checks that the credentials can list the repositories of
+// workspace, which any credentials serving its badges can, whatever their
+// authentication method.
+func (bb *BBCloud) CheckWorkspace(ctx context.Context, workspace string) error {
+	_, err := bb.queryBBURLOnce(ctx, bb.apiURL+url.PathEscape(workspace)+"?pagelen=1")
+	return err
+}
+
+// Check checks that shields.io renders badges.
+func (renderer ShieldsRenderer) Check(ctx context.Context) error {
+	client := renderer.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	if renderer.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, renderer.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequest("GET", generateBadgeURL(BadgeInfo{Label: "bitbadger", Message: "ready", Color: "green"}), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return newUpstreamError(resp)
+	}
+
+	return nil
+}
+
+// Check always succeeds, as badges are rendered locally.
+func (LocalRenderer) Check(ctx context.Context) error {
+	return nil
+}
+
+// Check always succeeds, as the cache is held in memory.
+func (cache *Cache) Check(ctx context.Context) error {
+	return nil
+}
+
+// Check checks the guarded provider, regardless of the circuit state.
+func (guarded *circuitBreakerProvider) Check(ctx context.Context) error {
+	if checker, ok := guarded.provider.(Checker); ok {
+		return checker.Check(ctx)
+	}
+
+	return nil
+}
+
+// readinessCheck runs a check at most once per readyCheckInterval. Once it
+// succeeded, failures of the upstream service, such as server errors, are
+// ignored: the server can still serve cached badges, and the circuit breaker
+// handles the outage. Other errors, such as invalid credentials, fail it.
+type readinessCheck struct {
+	mutex     sync.Mutex
+	name      string
+	checker   Checker
+	err       error
+	checkedAt time.Time
+	succeeded bool
+}
+
+func newReadinessCheck(name string, component interface{}) *readinessCheck {
+	checker, _ := component.(Checker)
+
+	return &readinessCheck{
+		name:    name,
+		checker: checker,
+	}
+}
+
+// run returns the result of the check, checking again if the last result is
+// older than readyCheckInterval.
+func (check *readinessCheck) run(ctx context.Context) error {
+	check.mutex.Lock()
+	defer check.mutex.Unlock()
+
+	if check.checker == nil {
+		return nil
+	}
+	if !check.checkedAt.IsZero() && time.Since(check.checkedAt) < readyCheckInterval {
+		return check.err
+	}
+
+	err := check.checker.Check(ctx)
+	if err != nil && ctx.Err() != nil {
+		// The probe was cancelled, which tells nothing about the component.
+		return err
+	}
+
+	switch {
+	case err == nil:
+		check.succeeded = true
+	case check.succeeded && isProviderFailure(err):
+		log.Warn("Ignoring failed ", check.name, " readiness check: ", err)
+		err = nil
+	default:
+		log.Error("Failed ", check.name, " readiness check: ", err)
+	}
+
+	check.err = err
+	check.checkedAt = time.Now()
+	return err
+}
+
+// Ready returns nil if the server is ready to serve badges: the credentials
+// are valid, and the cache and renderer are available. Otherwise, it returns
+// the error of the first failed check.
+func (server *Server) Ready(ctx context.Context) error {
+	for _, check := range server.readinessChecks {
+		err := check.run(ctx)
+		if err != nil {
+			return fmt.Errorf("%s: %s", check.name, err)
+		}
+	}
+
+	return nil
+}
+
+// serveHealth responds to the liveness probe.
+func (server *Server) serveHealth(w http.ResponseWriter) {
+	fmt.Fprint(w, "ok")
+}
+
+// serveReady responds to the readiness probe, listing the failed checks.
+func (server *Server) serveReady(w http.ResponseWriter, r *http.Request) {
+	failed := []string{}
+	for _, check := range server.readinessChecks {
+		if check.run(r.Context()) != nil {
+			failed = append(failed, check.name)
+		}
+	}
+
+	if len(failed) > 0 {
+		http.Error(w, "Not ready, failed checks: "+strings.Join(failed, ", "), http.StatusServiceUnavailable)
+		return
+	}
+
+	fmt.Fprint(w, "ok")
+}
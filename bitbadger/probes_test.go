@@ -0,0 +1,157 @@
+package bitbadger
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// checkedProvider is a fakeProvider whose check returns err.
+type checkedProvider struct {
+	fakeProvider
+	err    error
+	checks int
+}
+
+func (provider *checkedProvider) Check(ctx context.Context) error {
+	provider.checks++
+	return provider.err
+}
+
+func TestServerProbes(t *testing.T) {
+	cases := []struct {
+		path            string
+		err             error
+		expectedStatus  int
+		expectedContent string
+	}{
+		{HealthPath, errors.New("Connection refused"), http.StatusOK, "ok"},
+		{ReadyPath, nil, http.StatusOK, "ok"},
+		{ReadyPath, &UpstreamError{Kind: UpstreamUnauthorized, StatusCode: 401}, http.StatusServiceUnavailable, "provider"},
+		{ReadyPath, errors.New("Connection refused"), http.StatusServiceUnavailable, "provider"},
+	}
+
+	for _, c := range cases {
+		server := NewServer(WithProvider(&checkedProvider{err: c.err}), WithRenderer(LocalRenderer{}))
+
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, httptest.NewRequest("GET", c.path, nil))
+
+		if recorder.Code != c.expectedStatus {
+			t.Errorf("ServeHTTP: Expected status %d for '%s' with '%v', got %d", c.expectedStatus, c.path, c.err, recorder.Code)
+		}
+		if !strings.Contains(recorder.Body.String(), c.expectedContent) {
+			t.Errorf("ServeHTTP: Expected '%s' in the response to '%s', got '%s'", c.expectedContent, c.path, recorder.Body.String())
+		}
+	}
+}
+
+func TestReadinessCheck(t *testing.T) {
+	defaultInterval := readyCheckInterval
+	defer func() { readyCheckInterval = defaultInterval }()
+	readyCheckInterval = 0
+
+	provider := &checkedProvider{}
+	check := newReadinessCheck("provider", provider)
+
+	steps := []struct {
+		err         error
+		expectReady bool
+	}{
+		{errors.New("Connection refused"), false},
+		{nil, true},
+		// Once ready, upstream outages don't fail the check.
+		{&UpstreamError{Kind: UpstreamServerError, StatusCode: 503}, true},
+		{errors.New("Connection refused"), true},
+		{&UpstreamError{Kind: UpstreamUnauthorized, StatusCode: 401}, false},
+	}
+
+	for i, step := range steps {
+		provider.err = step.err
+		ready := check.run(context.Background()) == nil
+		if ready != step.expectReady {
+			t.Errorf("run: Expected ready %v at step %d, got %v", step.expectReady, i, ready)
+		}
+	}
+
+	readyCheckInterval = time.Hour
+	checks := provider.checks
+	check.run(context.Background())
+	check.run(context.Background())
+	if provider.checks != checks {
+		t.Errorf("run: Results should be reused within the check interval")
+	}
+}
+
+func TestBBCloudCheck(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ := r.BasicAuth()
+		if r.URL.Path != "/user" || username != "user" || password != "secret" && password != "no-account-scope" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if password == "no-account-scope" {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"username": "user"}`))
+	}))
+	defer upstream.Close()
+
+	cases := []struct {
+		password      string
+		expectedError bool
+	}{
+		{"secret", false},
+		{"no-account-scope", false},
+		{"wrong", true},
+	}
+
+	for _, c := range cases {
+		bb := NewBBCloud(Config{Username: "user", Password: c.password}, upstream.Client())
+		bb.apiURL = upstream.URL + "/repositories/"
+
+		err := bb.Check(context.Background())
+		if (err != nil) != c.expectedError {
+			t.Errorf("Check: Unexpected error '%v' with password '%s'", err, c.password)
+		}
+	}
+}
+
+func TestBBCloudCheckWorkspace(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("Authorization") != "Bearer token":
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		case r.URL.Path != "/repositories/acme":
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		default:
+			w.Write([]byte(`{"values": []}`))
+		}
+	}))
+	defer upstream.Close()
+
+	bb := NewBBCloud(Config{Auth: AccessTokenAuth, AccessToken: "token"}, upstream.Client())
+	bb.apiURL = upstream.URL + "/repositories/"
+
+	cases := []struct {
+		route         CredentialRoute
+		expectedError bool
+	}{
+		{CredentialRoute{Workspace: "acme", Provider: bb}, false},
+		{CredentialRoute{Workspace: "other", Provider: bb}, true},
+		// Patterns fall back to checking the credentials.
+		{CredentialRoute{Workspace: "acme-*", Provider: bb}, false},
+	}
+
+	for _, c := range cases {
+		err := newProviderRoute(c.route, DefaultCircuitBreakerPolicy).checker().Check(context.Background())
+		if (err != nil) != c.expectedError {
+			t.Errorf("Check: Unexpected error '%v' for workspace '%s'", err, c.route.Workspace)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package bitbadger
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimitPolicy holds how many requests are sent to the upstream repository
+// per account, to stay under its API quota.
+type RateLimitPolicy struct {
+	// RequestsPerHour is the sustained number of requests per account and per
+	// hour. Zero disables rate limiting.
+	RequestsPerHour int
+	// Burst is the number of requests which can be sent at once after a
+	// period of inactivity. It adds up to RequestsPerHour within an hour.
+	Burst int
+}
+
+// DefaultRateLimitPolicy keeps accounts under the BitBucket Cloud quota of
+// 1000 requests per hour.
+var DefaultRateLimitPolicy = RateLimitPolicy{
+	RequestsPerHour: 900,
+	Burst:           100,
+}
+
+// RateLimiter limits the requests sent to the upstream repository, with a
+// quota per account. Providers using the same accounts should share a rate
+// limiter, see WithBBRateLimiter. It is safe for concurrent use.
+type RateLimiter struct {
+	mutex    sync.Mutex
+	policy   RateLimitPolicy
+	accounts map[string]*tokenBucket
+}
+
+// NewRateLimiter returns a rate limiter applying policy to each account.
+func NewRateLimiter(policy RateLimitPolicy) *RateLimiter {
+	return &RateLimiter{
+		policy:   policy,
+		accounts: map[string]*tokenBucket{},
+	}
+}
+
+// SetPolicy changes the policy of the rate limiter. The quota used by each
+// account is reset.
+func (limiter *RateLimiter) SetPolicy(policy RateLimitPolicy) {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	limiter.policy = policy
+	limiter.accounts = map[string]*tokenBucket{}
+}
+
+// Policy returns the policy of the rate limiter.
+func (limiter *RateLimiter) Policy() RateLimitPolicy {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	return limiter.policy
+}
+
+// account returns the token bucket of an account, or nil if rate limiting is
+// disabled.
+func (limiter *RateLimiter) account(account string) *tokenBucket {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	if limiter.policy.RequestsPerHour <= 0 {
+		return nil
+	}
+
+	bucket, found := limiter.accounts[account]
+	if !found {
+		bucket = newTokenBucket(limiter.policy, time.Now())
+		limiter.accounts[account] = bucket
+	}
+
+	return bucket
+}
+
+var defaultRateLimiter = NewRateLimiter(DefaultRateLimitPolicy)
+
+// SetRateLimitPolicy sets the global rate limit policy. The quota used by
+// each account is reset.
+func SetRateLimitPolicy(policy RateLimitPolicy) {
+	defaultRateLimiter.SetPolicy(policy)
+}
+
+// GetRateLimitPolicy returns the current global rate limit policy.
+func GetRateLimitPolicy() RateLimitPolicy {
+	return defaultRateLimiter.Policy()
+}
+
+// tokenBucket allows a request per token. Tokens are added at a constant rate,
+// up to the capacity of the bucket. It is safe for concurrent use.
+type tokenBucket struct {
+	mutex    sync.Mutex
+	tokens   float64
+	capacity float64
+	// rate is the number of tokens added per second.
+	rate float64
+	last time.Time
+}
+
+func newTokenBucket(policy RateLimitPolicy, now time.Time) *tokenBucket {
+	capacity := float64(policy.Burst)
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &tokenBucket{
+		tokens:   capacity,
+		capacity: capacity,
+		rate:     float64(policy.RequestsPerHour) / time.Hour.Seconds(),
+		last:     now,
+	}
+}
+
+// refill adds the tokens earned since the last refill.
+func (bucket *tokenBucket) refill(now time.Time) {
+	if now.After(bucket.last) {
+		bucket.tokens += now.Sub(bucket.last).Seconds() * bucket.rate
+		if bucket.tokens > bucket.capacity {
+			bucket.tokens = bucket.capacity
+		}
+		bucket.last = now
+	}
+}
+
+// reserve takes a token, and returns how long to wait before it can be used.
+// Tokens are reserved in order, so waiting requests are served first come,
+// first served.
+func (bucket *tokenBucket) reserve(now time.Time) time.Duration {
+	bucket.mutex.Lock()
+	defer bucket.mutex.Unlock()
+
+	bucket.refill(now)
+	bucket.tokens--
+	if bucket.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-bucket.tokens / bucket.rate * float64(time.Second))
+}
+
+// cancel returns a reserved token which was not used.
+func (bucket *tokenBucket) cancel() {
+	bucket.mutex.Lock()
+	defer bucket.mutex.Unlock()
+
+	bucket.tokens++
+}
+
+// pause prevents any request for a duration, for instance when the upstream
+// server reports the quota as exhausted.
+func (bucket *tokenBucket) pause(now time.Time, duration time.Duration) {
+	bucket.mutex.Lock()
+	defer bucket.mutex.Unlock()
+
+	bucket.refill(now)
+	tokens := -duration.Seconds() * bucket.rate
+	if bucket.tokens > tokens {
+		bucket.tokens = tokens
+	}
+}
+
+// Wait blocks until a request can be sent. It fails immediately with a rate
+// limited error if ctx would expire first.
+func (bucket *tokenBucket) Wait(ctx context.Context) error {
+	now := time.Now()
+	delay := bucket.reserve(now)
+	if delay <= 0 {
+		return nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && deadline.Sub(now) < delay {
+		bucket.cancel()
+		return &UpstreamError{Kind: UpstreamRateLimited, RetryAfter: delay}
+	}
+
+	err := sleep(ctx, delay)
+	if err != nil {
+		bucket.cancel()
+	}
+
+	return err
+}
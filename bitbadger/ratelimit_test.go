@@ -0,0 +1,84 @@
+package bitbadger
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Date(2020, 12, 14, 10, 0, 0, 0, time.UTC)
+	bucket := newTokenBucket(RateLimitPolicy{RequestsPerHour: 3600, Burst: 2}, now)
+
+	// The burst is available at once, then a token is added every second.
+	steps := []struct {
+		elapsed  time.Duration
+		expected time.Duration
+	}{
+		{0, 0},
+		{0, 0},
+		{0, time.Second},
+		{0, 2 * time.Second},
+		{10 * time.Second, 0},
+	}
+
+	for i, step := range steps {
+		now = now.Add(step.elapsed)
+		delay := bucket.reserve(now)
+		if delay != step.expected {
+			t.Errorf("reserve: Expected a delay of %v at step %d, got %v", step.expected, i, delay)
+		}
+	}
+
+	bucket.pause(now, time.Minute)
+	if delay := bucket.reserve(now); delay < time.Minute {
+		t.Errorf("reserve: Expected a delay of at least a minute after a pause, got %v", delay)
+	}
+}
+
+func TestTokenBucketWaitDeadline(t *testing.T) {
+	bucket := newTokenBucket(RateLimitPolicy{RequestsPerHour: 1, Burst: 1}, time.Now())
+
+	err := bucket.Wait(context.Background())
+	if err != nil {
+		t.Fatalf("Wait: Unexpected error: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	err = bucket.Wait(ctx)
+	upstreamErr, ok := err.(*UpstreamError)
+	if !ok || upstreamErr.Kind != UpstreamRateLimited {
+		t.Errorf("Wait: Expected a rate limited error, got %v", err)
+	}
+}
+
+func TestRateLimiterAccounts(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitPolicy{RequestsPerHour: 100, Burst: 10})
+	if limiter.account("alice") != limiter.account("alice") {
+		t.Errorf("account: Providers of the same account should share a quota")
+	}
+	if limiter.account("alice") == limiter.account("bob") {
+		t.Errorf("account: Accounts should have their own quota")
+	}
+
+	limiter.SetPolicy(RateLimitPolicy{})
+	if limiter.account("alice") != nil {
+		t.Errorf("account: Rate limiting should be disabled")
+	}
+}
+
+func TestBBCloudRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitPolicy{RequestsPerHour: 100, Burst: 10})
+	alice := NewBBCloud(Config{Username: "alice"}, nil, WithBBRateLimiter(limiter))
+	aliceAgain := NewBBCloud(Config{Username: "alice"}, nil, WithBBRateLimiter(limiter))
+	if alice.rateLimiter.account(alice.auth.account()) != aliceAgain.rateLimiter.account(aliceAgain.auth.account()) {
+		t.Errorf("NewBBCloud: Providers sharing a rate limiter should share the quota of their account")
+	}
+
+	other := NewBBCloud(Config{Username: "alice"}, nil)
+	if other.rateLimiter.account(other.auth.account()) == alice.rateLimiter.account(alice.auth.account()) {
+		t.Errorf("NewBBCloud: Providers should have their own rate limiter by default")
+	}
+}
@@ -0,0 +1,231 @@
+package bitbadger
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// BadgeRequest holds the information relative to a client badge generation
+// request.
+type BadgeRequest struct {
+	Username   string
+	Repository string
+	Type       BadgeType
+	Branch     string
+	User       string
+	Days       int
+	JSON       bool
+	Kind       string
+	Priority   string
+	TagPrefix  string
+	PreRelease bool
+	Show       string
+	Label      string
+	Project    string
+	Include    string
+	Exclude    string
+	// Target, ExcludeAuthor and TitleMatch filter the pull requests
+	// considered by pull request badges.
+	Target        string
+	ExcludeAuthor string
+	TitleMatch    string
+	// Language and DurationFormat select how badge labels and messages are
+	// printed. Empty values select the defaults.
+	Language       string
+	DurationFormat string
+}
+
+// PullRequestsInfo holds the pull request data used to generate the badges.
+type PullRequestsInfo struct {
+	OpenCount           int
+	DraftCount          int
+	OldestOpenPR        time.Duration
+	OpenAverageTime     time.Duration
+	AveragePRMergeTime  time.Duration
+	MergeTimeSamples    int
+	AwaitingReviewCount int
+	AverageReviewers    float64
+	PendingReviewCount  int
+	MergedCount         int
+	MergedWindow        time.Duration
+}
+
+// mergedPRHistoryNeeds returns whether the badges of request show the merge
+// time and the throughput of merged pull requests, which providers only
+// retrieve when needed as they require more upstream queries.
+func mergedPRHistoryNeeds(request BadgeRequest) (mergeTime bool, throughput bool) {
+	switch request.Type {
+	case AveragePRMergeTime:
+		return true, false
+	case MergedPRsPerDayType, MergedPRsPerWeekType, MergedPRsPerMonthType:
+		return false, true
+	case HealthType:
+		return true, true
+	case SummaryType:
+		parts, _ := parseSummaryParts(request.Show)
+		for _, part := range parts {
+			partMergeTime, partThroughput := mergedPRHistoryNeeds(BadgeRequest{Type: part})
+			mergeTime = mergeTime || partMergeTime
+			throughput = throughput || partThroughput
+		}
+		return mergeTime, throughput
+	default:
+		return false, false
+	}
+}
+
+// BuildState represents the aggregated state of the builds of a commit.
+type BuildState string
+
+const (
+	// BuildPassing means all the builds succeeded.
+	BuildPassing BuildState = "passing"
+	// BuildFailing means at least one build failed or was stopped.
+	BuildFailing BuildState = "failing"
+	// BuildInProgress means at least one build is still running.
+	BuildInProgress BuildState = "in progress"
+	// BuildUnknown means no build was reported.
+	BuildUnknown BuildState = "unknown"
+)
+
+// BuildInfo holds the build data used to generate the build badges.
+type BuildInfo struct {
+	Branch        string
+	State         BuildState
+	PRsWithBuilds int
+	PRsPassing    int
+}
+
+// CommitsInfo holds the commit data used to generate the commit badges.
+type CommitsInfo struct {
+	Branch        string
+	LastCommitAge time.Duration
+	CommitCount   int
+	Window        time.Duration
+	Contributors  int
+	BusFactor     int
+}
+
+// BranchesInfo holds the branch data used to generate the branch badges.
+type BranchesInfo struct {
+	BranchCount    int
+	StaleBranches  []string
+	StaleThreshold time.Duration
+}
+
+// IssuesInfo holds the issue tracker data used to generate the issue badges.
+type IssuesInfo struct {
+	Kind            string
+	Priority        string
+	OpenCount       int
+	OldestOpenIssue time.Duration
+}
+
+// TagsInfo holds the tag data used to generate the tag badges.
+type TagsInfo struct {
+	LatestTag    string
+	LatestTagAge time.Duration
+}
+
+// RepositoryType holds the type of repository service targetted.
+type RepositoryType int
+
+const (
+	// BitBucketCloud represents BitBucket Cloud service.
+	BitBucketCloud RepositoryType = iota
+)
+
+// Provider retrieves the upstream data of badges from a repository service.
+type Provider interface {
+	RetrievePullRequestInfo(ctx context.Context, request BadgeRequest) (PullRequestsInfo, error)
+	RetrieveBuildInfo(ctx context.Context, request BadgeRequest) (BuildInfo, error)
+	RetrieveCommitsInfo(ctx context.Context, request BadgeRequest) (CommitsInfo, error)
+	RetrieveBranchesInfo(ctx context.Context, request BadgeRequest) (BranchesInfo, error)
+	RetrieveIssuesInfo(ctx context.Context, request BadgeRequest) (IssuesInfo, error)
+	RetrieveTagsInfo(ctx context.Context, request BadgeRequest) (TagsInfo, error)
+	// RetrieveRepositories retrieves the slugs of the repositories of the
+	// request workspace, restricted to the request project if any.
+	RetrieveRepositories(ctx context.Context, request BadgeRequest) ([]string, error)
+}
+
+// WorkspaceRepositories is the repository name used in requests to aggregate
+// the metrics of all the repositories of a workspace.
+const WorkspaceRepositories = "*"
+
+// RetrievePullRequestInfo retrieves information relative to pull requests
+// from a specific repository.
+func RetrievePullRequestInfo(repoType RepositoryType, request BadgeRequest) (PullRequestsInfo, error) {
+	switch repoType {
+	case BitBucketCloud:
+		return RetrieveBBPullRequestInfo(request)
+	default:
+		return PullRequestsInfo{}, errors.New("Invalid repository type")
+	}
+}
+
+// RetrieveBuildInfo retrieves information relative to builds from a specific
+// repository.
+func RetrieveBuildInfo(repoType RepositoryType, request BadgeRequest) (BuildInfo, error) {
+	switch repoType {
+	case BitBucketCloud:
+		return RetrieveBBBuildInfo(request)
+	default:
+		return BuildInfo{}, errors.New("Invalid repository type")
+	}
+}
+
+// RetrieveCommitsInfo retrieves information relative to the commits of a
+// branch from a specific repository.
+func RetrieveCommitsInfo(repoType RepositoryType, request BadgeRequest) (CommitsInfo, error) {
+	switch repoType {
+	case BitBucketCloud:
+		return RetrieveBBCommitsInfo(request)
+	default:
+		return CommitsInfo{}, errors.New("Invalid repository type")
+	}
+}
+
+// RetrieveBranchesInfo retrieves information relative to branches from a
+// specific repository.
+func RetrieveBranchesInfo(repoType RepositoryType, request BadgeRequest) (BranchesInfo, error) {
+	switch repoType {
+	case BitBucketCloud:
+		return RetrieveBBBranchesInfo(request)
+	default:
+		return BranchesInfo{}, errors.New("Invalid repository type")
+	}
+}
+
+// RetrieveIssuesInfo retrieves information relative to the issue tracker of a
+// specific repository.
+func RetrieveIssuesInfo(repoType RepositoryType, request BadgeRequest) (IssuesInfo, error) {
+	switch repoType {
+	case BitBucketCloud:
+		return RetrieveBBIssuesInfo(request)
+	default:
+		return IssuesInfo{}, errors.New("Invalid repository type")
+	}
+}
+
+// RetrieveTagsInfo retrieves information relative to tags from a specific
+// repository.
+func RetrieveTagsInfo(repoType RepositoryType, request BadgeRequest) (TagsInfo, error) {
+	switch repoType {
+	case BitBucketCloud:
+		return RetrieveBBTagsInfo(request)
+	default:
+		return TagsInfo{}, errors.New("Invalid repository type")
+	}
+}
+
+// RetrieveRepositories retrieves the slugs of the repositories of the
+// request workspace, restricted to the request project if any.
+func RetrieveRepositories(repoType RepositoryType, request BadgeRequest) ([]string, error) {
+	switch repoType {
+	case BitBucketCloud:
+		return RetrieveBBRepositories(request)
+	default:
+		return nil, errors.New("Invalid repository type")
+	}
+}
@@ -0,0 +1,877 @@
+package bitbadger
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// BBCloud retrieves badge data from BitBucket Cloud. It implements Provider.
+type BBCloud struct {
+	config      Config
+	client      *http.Client
+	auth        authenticator
+	apiURL      string
+	callTimeout time.Duration
+	retryPolicy RetryPolicy
+	rateLimiter *RateLimiter
+
+	drafts             *draftDetector
+	throughputPolicy   ThroughputPolicy
+	staleBranchPolicy  StaleBranchPolicy
+	contributorsPolicy ContributorsPolicy
+	workspacePolicy    WorkspacePolicy
+	schedule           *businessSchedule
+}
+
+// BBCloudOption configures a BitBucket Cloud provider, see NewBBCloud.
+type BBCloudOption func(bb *BBCloud)
+
+// WithBBRetryPolicy sets how requests failing with a temporary error are
+// retried. Defaults to DefaultRetryPolicy.
+func WithBBRetryPolicy(policy RetryPolicy) BBCloudOption {
+	return func(bb *BBCloud) {
+		bb.retryPolicy = policy
+	}
+}
+
+// WithBBRateLimiter sets the rate limiter of the requests. Providers using the
+// same accounts should share it, as the quota applies to each account. By
+// default, each provider has its own with DefaultRateLimitPolicy.
+func WithBBRateLimiter(limiter *RateLimiter) BBCloudOption {
+	return func(bb *BBCloud) {
+		bb.rateLimiter = limiter
+	}
+}
+
+// WithBBDraftPolicy sets how draft pull requests are detected and accounted
+// for. Invalid policies are ignored with an error logged, see
+// DraftPolicy.Validate. Defaults to DefaultDraftTitlePatterns, with drafts
+// included in the metrics.
+func WithBBDraftPolicy(policy DraftPolicy) BBCloudOption {
+	return func(bb *BBCloud) {
+		drafts, err := newDraftDetector(policy)
+		if err != nil {
+			log.Error("Ignoring invalid draft policy: ", err)
+			return
+		}
+
+		bb.drafts = drafts
+	}
+}
+
+// WithBBThroughputPolicy sets the window over which merged pull requests are
+// counted. Defaults to DefaultThroughputWindow.
+func WithBBThroughputPolicy(policy ThroughputPolicy) BBCloudOption {
+	return func(bb *BBCloud) {
+		bb.throughputPolicy = policy
+	}
+}
+
+// WithBBStaleBranchPolicy sets the age after which branches are stale.
+// Defaults to DefaultStaleBranchAge.
+func WithBBStaleBranchPolicy(policy StaleBranchPolicy) BBCloudOption {
+	return func(bb *BBCloud) {
+		bb.staleBranchPolicy = policy
+	}
+}
+
+// WithBBContributorsPolicy sets the window over which commit authors are
+// considered. Defaults to DefaultContributorsWindow.
+func WithBBContributorsPolicy(policy ContributorsPolicy) BBCloudOption {
+	return func(bb *BBCloud) {
+		bb.contributorsPolicy = policy
+	}
+}
+
+// WithBBWorkspacePolicy sets how many requests are sent concurrently to
+// retrieve the builds of pull requests. Defaults to DefaultWorkspaceWorkers.
+func WithBBWorkspacePolicy(policy WorkspacePolicy) BBCloudOption {
+	return func(bb *BBCloud) {
+		bb.workspacePolicy = policy
+	}
+}
+
+// WithBBBusinessHoursPolicy sets whether pull request durations are counted in
+// working hours. Invalid policies are ignored with an error logged, see
+// BusinessHoursPolicy.Validate. Defaults to wall-clock durations.
+func WithBBBusinessHoursPolicy(policy BusinessHoursPolicy) BBCloudOption {
+	return func(bb *BBCloud) {
+		schedule, err := newBusinessSchedule(policy)
+		if err != nil {
+			log.Error("Ignoring invalid business hours policy: ", err)
+			return
+		}
+
+		bb.schedule = schedule
+	}
+}
+
+// NewBBCloud returns a BitBucket Cloud provider authenticating with config,
+// sending requests with client, and configured with options. A nil client
+// uses http.DefaultClient. OAuth access tokens are shared by the requests of
+// the provider.
+func NewBBCloud(config Config, client *http.Client, options ...BBCloudOption) *BBCloud {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	bb := &BBCloud{
+		config:             config,
+		client:             client,
+		auth:               newAuthenticator(config, client),
+		apiURL:             bbAPIURL,
+		retryPolicy:        DefaultRetryPolicy,
+		throughputPolicy:   ThroughputPolicy{Window: DefaultThroughputWindow},
+		staleBranchPolicy:  StaleBranchPolicy{Age: DefaultStaleBranchAge},
+		contributorsPolicy: ContributorsPolicy{Window: DefaultContributorsWindow},
+		workspacePolicy:    WorkspacePolicy{Workers: DefaultWorkspaceWorkers},
+	}
+	for _, option := range options {
+		option(bb)
+	}
+
+	if bb.rateLimiter == nil {
+		bb.rateLimiter = NewRateLimiter(DefaultRateLimitPolicy)
+	}
+	if bb.drafts == nil {
+		bb.drafts, _ = newDraftDetector(DraftPolicy{TitlePatterns: DefaultDraftTitlePatterns})
+	}
+	if bb.schedule == nil {
+		bb.schedule, _ = newBusinessSchedule(defaultBusinessHoursPolicy())
+	}
+
+	return bb
+}
+
+// SetCallTimeout sets the maximum duration of each request to BitBucket,
+// including reading the response. Zero means no timeout.
+func (bb *BBCloud) SetCallTimeout(timeout time.Duration) {
+	bb.callTimeout = timeout
+}
+
+// Name returns the name of the provider, shown in the server status.
+func (bb *BBCloud) Name() string {
+	return "bbcloud"
+}
+
+// defaultBBCloud returns the provider configured with the global
+// configuration and policies, used by the package level functions.
+func defaultBBCloud() *BBCloud {
+	return NewBBCloud(config, client,
+		WithBBRetryPolicy(retryPolicy),
+		WithBBRateLimiter(defaultRateLimiter),
+		WithBBDraftPolicy(draftPolicy),
+		WithBBThroughputPolicy(throughputPolicy),
+		WithBBStaleBranchPolicy(staleBranchPolicy),
+		WithBBContributorsPolicy(contributorsPolicy),
+		WithBBWorkspacePolicy(workspacePolicy),
+		WithBBBusinessHoursPolicy(businessHoursPolicy))
+}
+
+// RetrieveBBPullRequestInfo retrieves information relative to pull requests
+// from BitBucket Cloud, using the global configuration.
+func RetrieveBBPullRequestInfo(request BadgeRequest) (PullRequestsInfo, error) {
+	return defaultBBCloud().RetrievePullRequestInfo(context.Background(), request)
+}
+
+// RetrieveBBBuildInfo retrieves information relative to builds from BitBucket
+// Cloud, using the global configuration.
+func RetrieveBBBuildInfo(request BadgeRequest) (BuildInfo, error) {
+	return defaultBBCloud().RetrieveBuildInfo(context.Background(), request)
+}
+
+// RetrieveBBCommitsInfo retrieves information relative to the commits of a
+// branch from BitBucket Cloud, using the global configuration.
+func RetrieveBBCommitsInfo(request BadgeRequest) (CommitsInfo, error) {
+	return defaultBBCloud().RetrieveCommitsInfo(context.Background(), request)
+}
+
+// RetrieveBBBranchesInfo retrieves information relative to branches from
+// BitBucket Cloud, using the global configuration.
+func RetrieveBBBranchesInfo(request BadgeRequest) (BranchesInfo, error) {
+	return defaultBBCloud().RetrieveBranchesInfo(context.Background(), request)
+}
+
+// RetrieveBBIssuesInfo retrieves information relative to the issue tracker
+// from BitBucket Cloud, using the global configuration.
+func RetrieveBBIssuesInfo(request BadgeRequest) (IssuesInfo, error) {
+	return defaultBBCloud().RetrieveIssuesInfo(context.Background(), request)
+}
+
+// RetrieveBBTagsInfo retrieves information relative to tags from BitBucket
+// Cloud, using the global configuration.
+func RetrieveBBTagsInfo(request BadgeRequest) (TagsInfo, error) {
+	return defaultBBCloud().RetrieveTagsInfo(context.Background(), request)
+}
+
+// RetrieveBBRepositories retrieves the slugs of the repositories of the
+// request workspace from BitBucket Cloud, using the global configuration.
+func RetrieveBBRepositories(request BadgeRequest) ([]string, error) {
+	return defaultBBCloud().RetrieveRepositories(context.Background(), request)
+}
+
+type bbUser struct {
+	DisplayName string `json:"display_name"`
+	Nickname    string `json:"nickname"`
+	AccountID   string `json:"account_id"`
+	UUID        string `json:"uuid"`
+}
+
+type bbParticipant struct {
+	User     bbUser `json:"user"`
+	Role     string `json:"role"`
+	Approved bool   `json:"approved"`
+	State    string `json:"state"`
+}
+
+type bbPullRequestEndpoint struct {
+	Branch struct {
+		Name string `json:"name"`
+	} `json:"branch"`
+}
+
+type bbPullRequest struct {
+	Title        string                `json:"title"`
+	ID           int                   `json:"id"`
+	Author       bbUser                `json:"author"`
+	CreatedOn    string                `json:"created_on"`
+	UpdatedOn    string                `json:"updated_on"`
+	Draft        bool                  `json:"draft"`
+	Source       bbPullRequestEndpoint `json:"source"`
+	Destination  bbPullRequestEndpoint `json:"destination"`
+	Reviewers    []bbUser              `json:"reviewers"`
+	Participants []bbParticipant       `json:"participants"`
+}
+
+type bbRepository struct {
+	MainBranch struct {
+		Name string `json:"name"`
+	} `json:"mainbranch"`
+}
+
+type bbCommitAuthor struct {
+	Raw  string `json:"raw"`
+	User bbUser `json:"user"`
+}
+
+type bbCommit struct {
+	Hash   string         `json:"hash"`
+	Date   string         `json:"date"`
+	Author bbCommitAuthor `json:"author"`
+}
+
+type bbBranch struct {
+	Name   string   `json:"name"`
+	Target bbCommit `json:"target"`
+}
+
+type bbPullRequestsReponse struct {
+	PullRequestsCount int             `json:"size"`
+	PullRequests      []bbPullRequest `json:"values"`
+	Page              int             `json:"page"`
+	PageLength        int             `json:"pagelen"`
+	NextPageURL       string          `json:"next"`
+	PreviousPageURL   string          `json:"previous"`
+}
+
+type openPRInfo struct {
+	OpenCount           int
+	DraftCount          int
+	OldestOpenPR        time.Duration
+	OpenAverageTime     time.Duration
+	AwaitingReviewCount int
+	AverageReviewers    float64
+	PendingReviewCount  int
+}
+
+type mergedPRInfo struct {
+	AveragePRMergeTime time.Duration
+	MergeTimeSamples   int
+	MergedCount        int
+	MergedWindow       time.Duration
+}
+
+// RetrievePullRequestInfo retrieves information relative to pull requests
+// from BitBucket Cloud.
+func (bb *BBCloud) RetrievePullRequestInfo(ctx context.Context, request BadgeRequest) (PullRequestsInfo, error) {
+	openPRInfo, err := bb.retrieveBBOpenPRInfo(ctx, request)
+	if err != nil {
+		return PullRequestsInfo{}, err
+	}
+
+	mergedPRInfo, err := bb.retrieveBBMergedPRInfo(ctx, request)
+	if err != nil {
+		return PullRequestsInfo{}, err
+	}
+
+	return PullRequestsInfo{
+		OpenCount:           openPRInfo.OpenCount,
+		DraftCount:          openPRInfo.DraftCount,
+		OldestOpenPR:        openPRInfo.OldestOpenPR,
+		OpenAverageTime:     openPRInfo.OpenAverageTime,
+		AwaitingReviewCount: openPRInfo.AwaitingReviewCount,
+		AverageReviewers:    openPRInfo.AverageReviewers,
+		PendingReviewCount:  openPRInfo.PendingReviewCount,
+		AveragePRMergeTime:  mergedPRInfo.AveragePRMergeTime,
+		MergeTimeSamples:    mergedPRInfo.MergeTimeSamples,
+		MergedCount:         mergedPRInfo.MergedCount,
+		MergedWindow:        mergedPRInfo.MergedWindow,
+	}, nil
+}
+
+const bbAPIURL = "https://api.bitbucket.org/2.0/repositories/"
+
+func (bb *BBCloud) queryBB(ctx context.Context, request BadgeRequest, endpoint string) ([]byte, error) {
+	sourceServerRequest := bb.apiURL
+	sourceServerRequest += request.Username + "/" + request.Repository
+	sourceServerRequest += endpoint
+
+	return bb.queryBBURL(ctx, sourceServerRequest)
+}
+
+// queryBBURL sends a GET request to BitBucket, retrying on temporary errors
+// according to the retry policy. Non-200 responses return an *UpstreamError.
+func (bb *BBCloud) queryBBURL(ctx context.Context, requestURL string) ([]byte, error) {
+	for retries := 0; ; retries++ {
+		body, err := bb.queryBBURLOnce(ctx, requestURL)
+		if err == nil {
+			return body, nil
+		}
+
+		delay, retry := bb.retryPolicy.retryDelay(err, retries)
+		if !retry {
+			return nil, err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return nil, err
+		}
+
+		log.Warn("Retrying BitBucket request in ", delay, ": ", err)
+		err = sleep(ctx, delay)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// queryBBURLOnce sends a single GET request to BitBucket, within the quota of
+// the account. If BitBucket rejects an OAuth access token, which can be
+// revoked before it expires, it is sent again once with a new token.
+func (bb *BBCloud) queryBBURLOnce(ctx context.Context, requestURL string) ([]byte, error) {
+	body, invalidated, err := bb.sendQuery(ctx, requestURL)
+	if invalidated {
+		log.Warn("BitBucket rejected the access token, retrying with a new one")
+		body, _, err = bb.sendQuery(ctx, requestURL)
+	}
+
+	return body, err
+}
+
+// sendQuery sends a GET request to BitBucket. It returns whether the
+// credentials were rejected and invalidated, in which case the request can be
+// sent again with new credentials.
+func (bb *BBCloud) sendQuery(ctx context.Context, requestURL string) ([]byte, bool, error) {
+	req, err := http.NewRequest("GET", requestURL, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	limiter := bb.rateLimiter.account(bb.auth.account())
+	if limiter != nil {
+		err = limiter.Wait(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	if bb.callTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, bb.callTimeout)
+		defer cancel()
+	}
+	req = req.WithContext(ctx)
+
+	err = bb.auth.authenticate(ctx, req)
+	if err != nil {
+		return nil, false, err
+	}
+
+	start := time.Now()
+	body, err := bb.send(req)
+	observeUpstreamRequest(bb.Name(), bbEndpointName(bb.apiURL, requestURL), time.Since(start), err)
+	if err != nil {
+		upstreamErr, ok := err.(*UpstreamError)
+		if ok && upstreamErr.RetryAfter > 0 && limiter != nil {
+			// Other requests of the account would be rejected as well, until then.
+			limiter.pause(time.Now(), upstreamErr.RetryAfter)
+		}
+		// Only 401 responses reject the credentials, 403 responses deny
+		// access to the resource.
+		invalidated := ok && upstreamErr.StatusCode == http.StatusUnauthorized && bb.auth.invalidate(req)
+		return nil, invalidated, err
+	}
+
+	log.Debug("BitBucket response:")
+	log.Debug(string(body))
+
+	return body, false, nil
+}
+
+// send sends a request and reads the response body. Non-200 responses are
+// returned as an *UpstreamError.
+func (bb *BBCloud) send(req *http.Request) ([]byte, error) {
+	resp, err := bb.client.Do(req)
+	if err != nil {
+		log.Error("Get request failed: ", err)
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		log.Error("Failed to read response: ", err)
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		log.Error("Non-200 response: ", resp.Status, "\n", string(body))
+		return nil, newUpstreamError(resp)
+	}
+
+	return body, nil
+}
+
+// bbEndpointName returns the endpoint of a request URL, without the workspace,
+// repository and identifiers, such as "pullrequests" or "refs/branches".
+func bbEndpointName(apiURL string, requestURL string) string {
+	path := strings.SplitN(requestURL, "?", 2)[0]
+	if !strings.HasPrefix(path, apiURL) {
+		// Endpoints outside of the repositories, such as "user".
+		return path[strings.LastIndex(path, "/")+1:]
+	}
+
+	path = strings.TrimPrefix(path, apiURL)
+	elements := strings.Split(strings.Trim(path, "/"), "/")
+
+	switch {
+	case len(elements) < 2:
+		return "workspace"
+	case len(elements) == 2:
+		return "repository"
+	case elements[2] == "refs" && len(elements) > 3:
+		return "refs/" + elements[3]
+	default:
+		return elements[2]
+	}
+}
+
+// bbPage holds the pagination fields common to all BitBucket list responses.
+type bbPage struct {
+	NextPageURL string `json:"next"`
+}
+
+// queryBBPages queries a paginated endpoint, calling handlePage with the body
+// of each page. It stops after the last page, or as soon as handlePage returns
+// false.
+func (bb *BBCloud) queryBBPages(ctx context.Context, request BadgeRequest, endpoint string, handlePage func(body []byte) (bool, error)) error {
+	return bb.queryBBURLPages(ctx, bb.apiURL+request.Username+"/"+request.Repository+endpoint, handlePage)
+}
+
+// queryBBURLPages is similar to queryBBPages, starting from the URL of the
+// first page.
+func (bb *BBCloud) queryBBURLPages(ctx context.Context, firstPageURL string, handlePage func(body []byte) (bool, error)) error {
+	body, err := bb.queryBBURL(ctx, firstPageURL)
+	if err != nil {
+		return err
+	}
+
+	for {
+		var page bbPage
+		err = json.Unmarshal(body, &page)
+		if err != nil {
+			log.Error("Answer decoding failed for:")
+			log.Error(body)
+			return err
+		}
+
+		more, err := handlePage(body)
+		if err != nil {
+			return err
+		}
+
+		if !more || page.NextPageURL == "" {
+			return nil
+		}
+
+		body, err = bb.queryBBURL(ctx, page.NextPageURL)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// queryBBPullRequests retrieves all the pull requests returned by the
+// endpoint, following the pagination links.
+func (bb *BBCloud) queryBBPullRequests(ctx context.Context, request BadgeRequest, endpoint string) ([]bbPullRequest, error) {
+	pullRequests := []bbPullRequest{}
+	err := bb.queryBBPages(ctx, request, endpoint, func(body []byte) (bool, error) {
+		var response bbPullRequestsReponse
+		err := json.Unmarshal(body, &response)
+		if err != nil {
+			log.Error("Answer decoding failed for:")
+			log.Error(body)
+			return false, err
+		}
+
+		pullRequests = append(pullRequests, response.PullRequests...)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pullRequests, nil
+}
+
+// bbOpenPullRequestsEndpoint lists open pull requests, including their
+// reviewers and participants which are not returned by default.
+const bbOpenPullRequestsEndpoint = "/pullrequests?state=OPEN&pagelen=50" +
+	"&fields=%2Bvalues.reviewers,%2Bvalues.participants"
+
+// retrieveBBOpenPullRequests retrieves all open pull requests, leaving out
+// drafts if the draft policy excludes them.
+func (bb *BBCloud) retrieveBBOpenPullRequests(ctx context.Context, request BadgeRequest) ([]bbPullRequest, error) {
+	pullRequests, err := bb.queryBBFilteredPullRequests(ctx, request, bbOpenPullRequestsEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bb.drafts.ExcludeDrafts {
+		return pullRequests, nil
+	}
+
+	nonDraftPullRequests := []bbPullRequest{}
+	for _, pullRequest := range pullRequests {
+		if !bb.drafts.isDraft(pullRequest.Draft, pullRequest.Title) {
+			nonDraftPullRequests = append(nonDraftPullRequests, pullRequest)
+		}
+	}
+
+	return nonDraftPullRequests, nil
+}
+
+// queryBBFilteredPullRequests retrieves all the pull requests returned by
+// the endpoint which match the request pull request filters.
+func (bb *BBCloud) queryBBFilteredPullRequests(ctx context.Context, request BadgeRequest, endpoint string) ([]bbPullRequest, error) {
+	filter, err := newBBPullRequestFilter(request)
+	if err != nil {
+		return nil, err
+	}
+
+	pullRequests, err := bb.queryBBPullRequests(ctx, request, endpoint+filter.QueryParameter())
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []bbPullRequest{}
+	for _, pullRequest := range pullRequests {
+		if filter.Matches(pullRequest) {
+			filtered = append(filtered, pullRequest)
+		}
+	}
+
+	return filtered, nil
+}
+
+// retrieveBBMainBranchName retrieves the name of the main branch of the
+// repository.
+func (bb *BBCloud) retrieveBBMainBranchName(ctx context.Context, request BadgeRequest) (string, error) {
+	body, err := bb.queryBB(ctx, request, "")
+	if err != nil {
+		return "", err
+	}
+
+	var repository bbRepository
+	err = json.Unmarshal(body, &repository)
+	if err != nil {
+		log.Error("Answer decoding failed for:")
+		log.Error(body)
+		return "", err
+	}
+
+	return repository.MainBranch.Name, nil
+}
+
+// retrieveBBBranch retrieves a branch of the repository, or its main branch
+// if branchName is empty.
+func (bb *BBCloud) retrieveBBBranch(ctx context.Context, request BadgeRequest, branchName string) (bbBranch, error) {
+	if branchName == "" {
+		var err error
+		branchName, err = bb.retrieveBBMainBranchName(ctx, request)
+		if err != nil {
+			return bbBranch{}, err
+		}
+	}
+
+	body, err := bb.queryBB(ctx, request, "/refs/branches/"+url.PathEscape(branchName))
+	if err != nil {
+		return bbBranch{}, err
+	}
+
+	var branch bbBranch
+	err = json.Unmarshal(body, &branch)
+	if err != nil {
+		log.Error("Answer decoding failed for:")
+		log.Error(body)
+		return bbBranch{}, err
+	}
+
+	return branch, nil
+}
+
+func (bb *BBCloud) retrieveBBOpenPRInfo(ctx context.Context, request BadgeRequest) (openPRInfo, error) {
+	pullRequests, err := bb.queryBBFilteredPullRequests(ctx, request, bbOpenPullRequestsEndpoint)
+	if err != nil {
+		return openPRInfo{}, err
+	}
+
+	openPRCount := 0
+	draftPRCount := 0
+	awaitingReviewCount := 0
+	pendingReviewCount := 0
+	reviewersCount := 0
+	openPRTotalTime := time.Duration(0)
+	oldestOpenPRAge := time.Duration(0)
+
+	now := time.Now()
+	prsWithValidTime := 0
+	for _, pullRequest := range pullRequests {
+		draft := bb.drafts.isDraft(pullRequest.Draft, pullRequest.Title)
+		if draft {
+			draftPRCount++
+			if bb.drafts.ExcludeDrafts {
+				continue
+			}
+		}
+
+		openPRCount++
+		reviewersCount += len(pullRequest.Reviewers)
+
+		// Drafts are not ready for review yet.
+		if !draft && !bbPullRequestReviewed(pullRequest) {
+			awaitingReviewCount++
+		}
+
+		if request.User != "" && bbReviewPending(pullRequest, request.User) {
+			pendingReviewCount++
+		}
+
+		createdOnTime, err := time.Parse(time.RFC3339, pullRequest.CreatedOn)
+		if err != nil {
+			log.Error("Failed to parse time:", pullRequest.CreatedOn)
+		} else {
+			openTime := bb.schedule.elapsed(createdOnTime, now)
+
+			if openTime > oldestOpenPRAge {
+				oldestOpenPRAge = openTime
+			}
+
+			openPRTotalTime += openTime
+			prsWithValidTime++
+		}
+	}
+
+	openPRAverageTime := time.Duration(0)
+	if prsWithValidTime > 0 {
+		openPRAverageTime = time.Duration(
+			openPRTotalTime.Minutes()/float64(prsWithValidTime)) * time.Minute
+	}
+
+	averageReviewers := float64(0)
+	if openPRCount > 0 {
+		averageReviewers = float64(reviewersCount) / float64(openPRCount)
+	}
+
+	return openPRInfo{
+		OpenCount:           openPRCount,
+		DraftCount:          draftPRCount,
+		OldestOpenPR:        oldestOpenPRAge,
+		OpenAverageTime:     openPRAverageTime,
+		AwaitingReviewCount: awaitingReviewCount,
+		AverageReviewers:    averageReviewers,
+		PendingReviewCount:  pendingReviewCount,
+	}, nil
+}
+
+// bbPullRequestReviewed returns true if any participant approved or requested
+// changes on the pull request.
+func bbPullRequestReviewed(pullRequest bbPullRequest) bool {
+	for _, participant := range pullRequest.Participants {
+		if participant.Approved || participant.State == "changes_requested" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bbReviewPending returns true if user is a reviewer of the pull request, and
+// has neither approved it nor requested changes yet.
+func bbReviewPending(pullRequest bbPullRequest, user string) bool {
+	reviewer := false
+	for _, pullRequestReviewer := range pullRequest.Reviewers {
+		if bbUserMatches(pullRequestReviewer, user) {
+			reviewer = true
+			break
+		}
+	}
+
+	if !reviewer {
+		return false
+	}
+
+	for _, participant := range pullRequest.Participants {
+		if bbUserMatches(participant.User, user) &&
+			(participant.Approved || participant.State == "changes_requested") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// bbUserMatches returns true if user is the nickname, account ID or UUID of
+// the BitBucket account.
+func bbUserMatches(account bbUser, user string) bool {
+	if user == account.Nickname || user == account.AccountID {
+		return true
+	}
+
+	return account.UUID != "" && strings.Trim(account.UUID, "{}") == strings.Trim(user, "{}")
+}
+
+// retrieveBBMergedPRInfo retrieves the merge time and the throughput of
+// merged pull requests, if the badges of the request show them.
+func (bb *BBCloud) retrieveBBMergedPRInfo(ctx context.Context, request BadgeRequest) (mergedPRInfo, error) {
+	filter, err := newBBPullRequestFilter(request)
+	if err != nil {
+		return mergedPRInfo{}, err
+	}
+
+	info := mergedPRInfo{}
+	mergeTime, throughput := mergedPRHistoryNeeds(request)
+	if mergeTime {
+		info.AveragePRMergeTime, info.MergeTimeSamples, err = bb.retrieveBBMergeTime(ctx, request, filter)
+		if err != nil {
+			return mergedPRInfo{}, err
+		}
+	}
+	if throughput {
+		info.MergedCount, info.MergedWindow, err = bb.retrieveBBMergedCount(ctx, request, filter)
+		if err != nil {
+			return mergedPRInfo{}, err
+		}
+	}
+
+	return info, nil
+}
+
+// retrieveBBMergeTime returns the average merge time of the merged pull
+// requests of the first page returned by BitBucket, and the number of pull
+// requests averaged.
+func (bb *BBCloud) retrieveBBMergeTime(ctx context.Context, request BadgeRequest, filter bbPullRequestFilter) (time.Duration, int, error) {
+	body, err := bb.queryBB(ctx, request, "/pullrequests?state=MERGED"+filter.QueryParameter())
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var response bbPullRequestsReponse
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		log.Error("Answer decoding failed for:")
+		log.Error(body)
+		return 0, 0, err
+	}
+
+	mergedPRTotalTime := time.Duration(0)
+	mergedPRConsidered := 0
+
+	for _, pullRequest := range response.PullRequests {
+		if !filter.Matches(pullRequest) {
+			continue
+		}
+
+		// TODO: Use the activity feed of each PR instead. This value will be
+		// incorrect if the PR is updated after it has been merged.
+		createdOnTime, createdOnErr := time.Parse(time.RFC3339, pullRequest.CreatedOn)
+		updatedOnTime, updatedOnErr := time.Parse(time.RFC3339, pullRequest.UpdatedOn)
+		if createdOnErr != nil || updatedOnErr != nil {
+			log.Error("Failed to parse time:", pullRequest.CreatedOn, " or ", pullRequest.UpdatedOn)
+		} else {
+			openTime := bb.schedule.elapsed(createdOnTime, updatedOnTime)
+			mergedPRTotalTime += openTime
+			mergedPRConsidered++
+		}
+	}
+
+	averagePRMergeTime := time.Duration(0)
+	if mergedPRConsidered > 0 {
+		averagePRMergeTime = time.Duration(
+			mergedPRTotalTime.Minutes()/float64(mergedPRConsidered)) * time.Minute
+	}
+
+	return averagePRMergeTime, mergedPRConsidered, nil
+}
+
+// retrieveBBMergedCount returns the number of pull requests merged within the
+// throughput window of the request, and the window.
+func (bb *BBCloud) retrieveBBMergedCount(ctx context.Context, request BadgeRequest, filter bbPullRequestFilter) (int, time.Duration, error) {
+	throughputWindow := bb.throughputPolicy.requestWindow(request)
+	windowStart := time.Now().Add(-throughputWindow)
+	mergedInWindow := 0
+
+	// Most recently merged PRs come first, so that pagination can stop once
+	// past the throughput window.
+	endpoint := "/pullrequests?state=MERGED&sort=-updated_on&pagelen=50" + filter.QueryParameter()
+	err := bb.queryBBPages(ctx, request, endpoint, func(body []byte) (bool, error) {
+		var response bbPullRequestsReponse
+		err := json.Unmarshal(body, &response)
+		if err != nil {
+			log.Error("Answer decoding failed for:")
+			log.Error(body)
+			return false, err
+		}
+
+		pastWindow := false
+		for _, pullRequest := range response.PullRequests {
+			updatedOnTime, err := time.Parse(time.RFC3339, pullRequest.UpdatedOn)
+			if err != nil {
+				log.Error("Failed to parse time:", pullRequest.UpdatedOn)
+				continue
+			}
+
+			if updatedOnTime.Before(windowStart) {
+				pastWindow = true
+				continue
+			}
+
+			if filter.Matches(pullRequest) {
+				mergedInWindow++
+			}
+		}
+
+		return !pastWindow, nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return mergedInWindow, throughputWindow, nil
+}
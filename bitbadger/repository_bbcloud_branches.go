@@ -0,0 +1,92 @@
+package bitbadger
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+type bbBranchesResponse struct {
+	Branches []bbBranch `json:"values"`
+}
+
+// RetrieveBranchesInfo retrieves information relative to branches from
+// BitBucket Cloud.
+func (bb *BBCloud) RetrieveBranchesInfo(ctx context.Context, request BadgeRequest) (BranchesInfo, error) {
+	mainBranchName, err := bb.retrieveBBMainBranchName(ctx, request)
+	if err != nil {
+		return BranchesInfo{}, err
+	}
+
+	branches, err := bb.queryBBBranches(ctx, request)
+	if err != nil {
+		return BranchesInfo{}, err
+	}
+
+	// Drafts have a PR too, so they are always considered here.
+	pullRequests, err := bb.queryBBPullRequests(ctx, request, bbOpenPullRequestsEndpoint)
+	if err != nil {
+		return BranchesInfo{}, err
+	}
+
+	staleThreshold := bb.staleBranchPolicy.requestAge(request)
+	return BranchesInfo{
+		BranchCount:    len(branches),
+		StaleBranches:  findBBStaleBranches(branches, pullRequests, mainBranchName, time.Now().Add(-staleThreshold)),
+		StaleThreshold: staleThreshold,
+	}, nil
+}
+
+func (bb *BBCloud) queryBBBranches(ctx context.Context, request BadgeRequest) ([]bbBranch, error) {
+	branches := []bbBranch{}
+	err := bb.queryBBPages(ctx, request, "/refs/branches?pagelen=100", func(body []byte) (bool, error) {
+		var response bbBranchesResponse
+		err := json.Unmarshal(body, &response)
+		if err != nil {
+			log.Error("Answer decoding failed for:")
+			log.Error(body)
+			return false, err
+		}
+
+		branches = append(branches, response.Branches...)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return branches, nil
+}
+
+// findBBStaleBranches returns the sorted names of the branches whose tip
+// commit is older than staleBefore, and that are not the source of an open
+// pull request. The main branch is never stale.
+func findBBStaleBranches(branches []bbBranch, openPullRequests []bbPullRequest, mainBranchName string, staleBefore time.Time) []string {
+	branchesWithPR := make(map[string]bool)
+	for _, pullRequest := range openPullRequests {
+		branchesWithPR[pullRequest.Source.Branch.Name] = true
+	}
+
+	staleBranches := []string{}
+	for _, branch := range branches {
+		if branch.Name == mainBranchName || branchesWithPR[branch.Name] {
+			continue
+		}
+
+		tipTime, err := time.Parse(time.RFC3339, branch.Target.Date)
+		if err != nil {
+			log.Error("Failed to parse time:", branch.Target.Date)
+			continue
+		}
+
+		if tipTime.Before(staleBefore) {
+			staleBranches = append(staleBranches, branch.Name)
+		}
+	}
+
+	sort.Strings(staleBranches)
+	return staleBranches
+}
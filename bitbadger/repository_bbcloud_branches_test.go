@@ -0,0 +1,35 @@
+package bitbadger
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFindBBStaleBranches(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-100 * 24 * time.Hour).Format(time.RFC3339)
+	recent := now.Add(-2 * 24 * time.Hour).Format(time.RFC3339)
+
+	branch := func(name, date string) bbBranch {
+		return bbBranch{Name: name, Target: bbCommit{Date: date}}
+	}
+	branches := []bbBranch{
+		branch("master", old),
+		branch("feature/recent", recent),
+		branch("feature/old-with-pr", old),
+		branch("feature/old", old),
+		branch("bugfix/old", old),
+		branch("invalid-date", "yesterday"),
+	}
+
+	var pullRequest bbPullRequest
+	pullRequest.Source.Branch.Name = "feature/old-with-pr"
+
+	staleBranches := findBBStaleBranches(branches, []bbPullRequest{pullRequest}, "master", now.Add(-90*24*time.Hour))
+
+	expected := []string{"bugfix/old", "feature/old"}
+	if !reflect.DeepEqual(staleBranches, expected) {
+		t.Errorf("findBBStaleBranches: Expected %v, got %v", expected, staleBranches)
+	}
+}
@@ -0,0 +1,128 @@
+package bitbadger
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+type bbCommitStatus struct {
+	Key   string `json:"key"`
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
+type bbCommitStatusesResponse struct {
+	Statuses []bbCommitStatus `json:"values"`
+}
+
+// RetrieveBuildInfo retrieves information relative to builds from
+// BitBucket Cloud.
+func (bb *BBCloud) RetrieveBuildInfo(ctx context.Context, request BadgeRequest) (BuildInfo, error) {
+	switch request.Type {
+	case PRBuildPassRateType:
+		return bb.retrieveBBPRBuildInfo(ctx, request)
+	default:
+		return bb.retrieveBBBranchBuildInfo(ctx, request)
+	}
+}
+
+func (bb *BBCloud) retrieveBBBranchBuildInfo(ctx context.Context, request BadgeRequest) (BuildInfo, error) {
+	branch, err := bb.retrieveBBBranch(ctx, request, request.Branch)
+	if err != nil {
+		return BuildInfo{}, err
+	}
+
+	statuses, err := bb.queryBBCommitStatuses(ctx, request, "/commit/"+branch.Target.Hash+"/statuses")
+	if err != nil {
+		return BuildInfo{}, err
+	}
+
+	return BuildInfo{
+		Branch: branch.Name,
+		State:  aggregateBBBuildState(statuses),
+	}, nil
+}
+
+func (bb *BBCloud) retrieveBBPRBuildInfo(ctx context.Context, request BadgeRequest) (BuildInfo, error) {
+	pullRequests, err := bb.retrieveBBOpenPullRequests(ctx, request)
+	if err != nil {
+		return BuildInfo{}, err
+	}
+
+	// Statuses are queried concurrently, bounded like workspace badges, to
+	// avoid one sequential call per open PR.
+	states := make([]BuildState, len(pullRequests))
+	errs := make([]error, len(pullRequests))
+	err = bb.workspacePolicy.runWorkers(ctx, len(pullRequests), func(i int) {
+		endpoint := "/pullrequests/" + strconv.Itoa(pullRequests[i].ID) + "/statuses"
+		statuses, err := bb.queryBBCommitStatuses(ctx, request, endpoint)
+		states[i], errs[i] = aggregateBBBuildState(statuses), err
+	})
+	if err != nil {
+		return BuildInfo{}, err
+	}
+
+	buildInfo := BuildInfo{}
+	for i, state := range states {
+		if errs[i] != nil {
+			return BuildInfo{}, errs[i]
+		}
+
+		// PRs without any build are not considered, as they can't be green.
+		if state == BuildUnknown {
+			continue
+		}
+
+		buildInfo.PRsWithBuilds++
+		if state == BuildPassing {
+			buildInfo.PRsPassing++
+		}
+	}
+
+	return buildInfo, nil
+}
+
+func (bb *BBCloud) queryBBCommitStatuses(ctx context.Context, request BadgeRequest, endpoint string) ([]bbCommitStatus, error) {
+	statuses := []bbCommitStatus{}
+	err := bb.queryBBPages(ctx, request, endpoint, func(body []byte) (bool, error) {
+		var response bbCommitStatusesResponse
+		err := json.Unmarshal(body, &response)
+		if err != nil {
+			log.Error("Answer decoding failed for:")
+			log.Error(body)
+			return false, err
+		}
+
+		statuses = append(statuses, response.Statuses...)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return statuses, nil
+}
+
+// aggregateBBBuildState returns the overall state of a set of BitBucket
+// commit statuses. Any failure wins over running builds, which win over
+// successful ones.
+func aggregateBBBuildState(statuses []bbCommitStatus) BuildState {
+	if len(statuses) == 0 {
+		return BuildUnknown
+	}
+
+	state := BuildPassing
+	for _, status := range statuses {
+		switch status.State {
+		case "FAILED", "STOPPED":
+			return BuildFailing
+		case "INPROGRESS":
+			state = BuildInProgress
+		}
+	}
+
+	return state
+}
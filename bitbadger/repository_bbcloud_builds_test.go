@@ -0,0 +1,81 @@
+package bitbadger
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAggregateBBBuildState(t *testing.T) {
+	cases := []struct {
+		in       []string
+		expected BuildState
+	}{
+		{[]string{}, BuildUnknown},
+		{[]string{"SUCCESSFUL", "SUCCESSFUL"}, BuildPassing},
+		{[]string{"SUCCESSFUL", "INPROGRESS"}, BuildInProgress},
+		{[]string{"INPROGRESS", "FAILED"}, BuildFailing},
+		{[]string{"STOPPED", "SUCCESSFUL"}, BuildFailing},
+	}
+
+	for _, c := range cases {
+		statuses := []bbCommitStatus{}
+		for _, state := range c.in {
+			statuses = append(statuses, bbCommitStatus{State: state})
+		}
+
+		state := aggregateBBBuildState(statuses)
+		if state != c.expected {
+			t.Errorf("aggregateBBBuildState: Expected '%s' for %v, got '%s'", c.expected, c.in, state)
+		}
+	}
+}
+
+func TestBBCloudRetrievePRBuildInfo(t *testing.T) {
+	var running, maxRunning int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/team/repo/pullrequests" {
+			fmt.Fprint(w, `{"values": [{"id": 1}, {"id": 2}, {"id": 3}, {"id": 4}, {"id": 5}]}`)
+			return
+		}
+
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		switch r.URL.Path {
+		case "/team/repo/pullrequests/1/statuses", "/team/repo/pullrequests/2/statuses":
+			fmt.Fprint(w, `{"values": [{"state": "SUCCESSFUL"}]}`)
+		case "/team/repo/pullrequests/3/statuses":
+			fmt.Fprint(w, `{"values": [{"state": "FAILED"}]}`)
+		default:
+			fmt.Fprint(w, `{"values": []}`)
+		}
+	}))
+	defer upstream.Close()
+
+	bb := NewBBCloud(Config{Username: "user", Password: "secret"}, upstream.Client(), WithBBRateLimiter(NewRateLimiter(RateLimitPolicy{})),
+		WithBBWorkspacePolicy(WorkspacePolicy{Workers: 2}))
+	bb.apiURL = upstream.URL + "/"
+
+	buildInfo, err := bb.RetrieveBuildInfo(context.Background(), BadgeRequest{Username: "team", Repository: "repo", Type: PRBuildPassRateType})
+	if err != nil {
+		t.Fatalf("RetrieveBuildInfo: Unexpected error: %s", err)
+	}
+	if buildInfo.PRsWithBuilds != 3 || buildInfo.PRsPassing != 2 {
+		t.Errorf("RetrieveBuildInfo: Unexpected build info %+v", buildInfo)
+	}
+	if maxRunning > 2 {
+		t.Errorf("RetrieveBuildInfo: Expected at most 2 concurrent status queries, got %d", maxRunning)
+	}
+}
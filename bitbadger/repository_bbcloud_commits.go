@@ -0,0 +1,124 @@
+package bitbadger
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+type bbCommitsResponse struct {
+	Commits []bbCommit `json:"values"`
+}
+
+// RetrieveCommitsInfo retrieves information relative to the commits of a
+// branch from BitBucket Cloud.
+func (bb *BBCloud) RetrieveCommitsInfo(ctx context.Context, request BadgeRequest) (CommitsInfo, error) {
+	branch, err := bb.retrieveBBBranch(ctx, request, request.Branch)
+	if err != nil {
+		return CommitsInfo{}, err
+	}
+
+	commitsInfo := CommitsInfo{
+		Branch: branch.Name,
+	}
+
+	lastCommitTime, err := time.Parse(time.RFC3339, branch.Target.Date)
+	if err != nil {
+		log.Error("Failed to parse time:", branch.Target.Date)
+	} else {
+		commitsInfo.LastCommitAge = time.Since(lastCommitTime)
+	}
+
+	switch request.Type {
+	case CommitActivityType:
+		commitsInfo.Window = bb.throughputPolicy.requestWindow(request)
+	case ContributorsType, BusFactorType:
+		commitsInfo.Window = bb.contributorsPolicy.requestWindow(request)
+	default:
+		return commitsInfo, nil
+	}
+
+	authorCommits := make(map[string]int)
+	err = bb.forEachBBCommitSince(ctx, request, branch.Target.Hash, time.Now().Add(-commitsInfo.Window), func(commit bbCommit) {
+		commitsInfo.CommitCount++
+		authorCommits[bbCommitAuthorID(commit.Author)]++
+	})
+	if err != nil {
+		return CommitsInfo{}, err
+	}
+
+	commitsInfo.Contributors = len(authorCommits)
+	commitsInfo.BusFactor = busFactor(authorCommits)
+	return commitsInfo, nil
+}
+
+// forEachBBCommitSince calls handleCommit for each commit reachable from
+// revision that was authored after since.
+func (bb *BBCloud) forEachBBCommitSince(ctx context.Context, request BadgeRequest, revision string, since time.Time, handleCommit func(commit bbCommit)) error {
+	return bb.queryBBPages(ctx, request, "/commits/"+revision+"?pagelen=100", func(body []byte) (bool, error) {
+		var response bbCommitsResponse
+		err := json.Unmarshal(body, &response)
+		if err != nil {
+			log.Error("Answer decoding failed for:")
+			log.Error(body)
+			return false, err
+		}
+
+		// Commits are in topological order, so merged branches may bring
+		// older commits early. Only stop once a full page is out of range.
+		pageInRange := false
+		for _, commit := range response.Commits {
+			commitTime, err := time.Parse(time.RFC3339, commit.Date)
+			if err != nil {
+				log.Error("Failed to parse time:", commit.Date)
+				continue
+			}
+
+			if !commitTime.Before(since) {
+				handleCommit(commit)
+				pageInRange = true
+			}
+		}
+
+		return pageInRange, nil
+	})
+}
+
+// bbCommitAuthorID identifies a commit author by their BitBucket account if
+// it is known, or by their raw author string otherwise.
+func bbCommitAuthorID(author bbCommitAuthor) string {
+	if author.User.AccountID != "" {
+		return author.User.AccountID
+	}
+
+	return author.Raw
+}
+
+// busFactor returns the minimum number of authors responsible for at least
+// half of the commits, given the number of commits of each author.
+func busFactor(authorCommits map[string]int) int {
+	counts := make([]int, 0, len(authorCommits))
+	totalCommits := 0
+	for _, count := range authorCommits {
+		counts = append(counts, count)
+		totalCommits += count
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(counts)))
+
+	authors := 0
+	commits := 0
+	for _, count := range counts {
+		if 2*commits >= totalCommits {
+			break
+		}
+
+		commits += count
+		authors++
+	}
+
+	return authors
+}
@@ -0,0 +1,39 @@
+package bitbadger
+
+import (
+	"testing"
+)
+
+func TestBusFactor(t *testing.T) {
+	cases := []struct {
+		in       map[string]int
+		expected int
+	}{
+		{map[string]int{}, 0},
+		{map[string]int{"alice": 10}, 1},
+		{map[string]int{"alice": 5, "bob": 5}, 1},
+		{map[string]int{"alice": 4, "bob": 3, "carol": 3}, 2},
+		{map[string]int{"alice": 1, "bob": 1, "carol": 1, "dave": 1, "erin": 1}, 3},
+	}
+
+	for _, c := range cases {
+		if factor := busFactor(c.in); factor != c.expected {
+			t.Errorf("busFactor: Expected %d for %v, got %d", c.expected, c.in, factor)
+		}
+	}
+}
+
+func TestBBCommitAuthorID(t *testing.T) {
+	withAccount := bbCommitAuthor{
+		Raw:  "Alice <alice@example.com>",
+		User: bbUser{AccountID: "1234:abcd"},
+	}
+	if bbCommitAuthorID(withAccount) != "1234:abcd" {
+		t.Errorf("bbCommitAuthorID: Should use the account ID when known")
+	}
+
+	withoutAccount := bbCommitAuthor{Raw: "Bob <bob@example.com>"}
+	if bbCommitAuthorID(withoutAccount) != "Bob <bob@example.com>" {
+		t.Errorf("bbCommitAuthorID: Should fall back to the raw author")
+	}
+}
@@ -0,0 +1,70 @@
+package bitbadger
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// bbPullRequestFilter applies the pull request filters of a request. The
+// destination branch is filtered by BitBucket, other filters are applied
+// once pull requests are retrieved.
+type bbPullRequestFilter struct {
+	target         string
+	excludeAuthors []string
+	titleMatch     *regexp.Regexp
+}
+
+func newBBPullRequestFilter(request BadgeRequest) (bbPullRequestFilter, error) {
+	filter := bbPullRequestFilter{
+		target: request.Target,
+	}
+
+	if request.ExcludeAuthor != "" {
+		filter.excludeAuthors = strings.Split(request.ExcludeAuthor, ",")
+	}
+
+	if request.TitleMatch != "" {
+		titleMatch, err := regexp.Compile(request.TitleMatch)
+		if err != nil {
+			return bbPullRequestFilter{}, err
+		}
+		filter.titleMatch = titleMatch
+	}
+
+	return filter, nil
+}
+
+// QueryParameter returns the "q" query parameter to append to pull request
+// endpoints, or an empty string if BitBucket has nothing to filter.
+func (filter bbPullRequestFilter) QueryParameter() string {
+	if filter.target == "" {
+		return ""
+	}
+
+	query := "destination.branch.name=" + bbQuoteString(filter.target)
+	return "&q=" + url.QueryEscape(query)
+}
+
+// Matches returns true if the pull request passes the filters which are not
+// applied by BitBucket.
+func (filter bbPullRequestFilter) Matches(pullRequest bbPullRequest) bool {
+	for _, excludedAuthor := range filter.excludeAuthors {
+		if bbUserMatches(pullRequest.Author, excludedAuthor) {
+			return false
+		}
+	}
+
+	if filter.titleMatch != nil && !filter.titleMatch.MatchString(pullRequest.Title) {
+		return false
+	}
+
+	return true
+}
+
+// bbQuoteString quotes a string value for the BitBucket query language.
+func bbQuoteString(value string) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, `"`, `\"`, -1)
+	return `"` + value + `"`
+}
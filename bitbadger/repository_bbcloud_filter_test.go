@@ -0,0 +1,53 @@
+package bitbadger
+
+import (
+	"testing"
+)
+
+func TestBBPullRequestFilter(t *testing.T) {
+	filter, err := newBBPullRequestFilter(BadgeRequest{
+		Target:        "main",
+		ExcludeAuthor: "renovate-bot,{dependabot-uuid}",
+		TitleMatch:    "^(feat|fix):",
+	})
+	if err != nil {
+		t.Fatalf("newBBPullRequestFilter: Unexpected error: %s", err)
+	}
+
+	expectedQuery := "&q=destination.branch.name%3D%22main%22"
+	if filter.QueryParameter() != expectedQuery {
+		t.Errorf("QueryParameter: Expected '%s', got '%s'", expectedQuery, filter.QueryParameter())
+	}
+
+	cases := []struct {
+		pullRequest bbPullRequest
+		expected    bool
+	}{
+		{bbPullRequest{Title: "feat: Add badges", Author: bbUser{Nickname: "alice"}}, true},
+		{bbPullRequest{Title: "Add badges", Author: bbUser{Nickname: "alice"}}, false},
+		{bbPullRequest{Title: "fix: Update deps", Author: bbUser{Nickname: "renovate-bot"}}, false},
+		{bbPullRequest{Title: "fix: Update deps", Author: bbUser{UUID: "{dependabot-uuid}"}}, false},
+	}
+
+	for i, c := range cases {
+		if filter.Matches(c.pullRequest) != c.expected {
+			t.Errorf("Matches: Expected %t for case %d", c.expected, i)
+		}
+	}
+
+	noFilter, _ := newBBPullRequestFilter(BadgeRequest{})
+	if noFilter.QueryParameter() != "" || !noFilter.Matches(bbPullRequest{Title: "Anything"}) {
+		t.Errorf("newBBPullRequestFilter: An empty filter should match everything")
+	}
+
+	_, err = newBBPullRequestFilter(BadgeRequest{TitleMatch: "("})
+	if err == nil {
+		t.Errorf("newBBPullRequestFilter: Should generate an error for invalid title patterns")
+	}
+}
+
+func TestBBQuoteString(t *testing.T) {
+	if quoted := bbQuoteString(`release/"1.0"\`); quoted != `"release/\"1.0\"\\"` {
+		t.Errorf("bbQuoteString: Unexpected quoted string %s", quoted)
+	}
+}
@@ -0,0 +1,89 @@
+package bitbadger
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+type bbIssue struct {
+	ID        int    `json:"id"`
+	Title     string `json:"title"`
+	State     string `json:"state"`
+	Kind      string `json:"kind"`
+	Priority  string `json:"priority"`
+	CreatedOn string `json:"created_on"`
+}
+
+type bbIssuesResponse struct {
+	Issues []bbIssue `json:"values"`
+}
+
+// RetrieveIssuesInfo retrieves information relative to open issues from
+// the BitBucket Cloud issue tracker.
+func (bb *BBCloud) RetrieveIssuesInfo(ctx context.Context, request BadgeRequest) (IssuesInfo, error) {
+	issues, err := bb.queryBBIssues(ctx, request, bbOpenIssuesQuery(request.Kind, request.Priority))
+	if err != nil {
+		return IssuesInfo{}, err
+	}
+
+	oldestOpenIssueAge := time.Duration(0)
+	now := time.Now()
+	for _, issue := range issues {
+		createdOnTime, err := time.Parse(time.RFC3339, issue.CreatedOn)
+		if err != nil {
+			log.Error("Failed to parse time:", issue.CreatedOn)
+			continue
+		}
+
+		if openTime := now.Sub(createdOnTime); openTime > oldestOpenIssueAge {
+			oldestOpenIssueAge = openTime
+		}
+	}
+
+	return IssuesInfo{
+		Kind:            request.Kind,
+		Priority:        request.Priority,
+		OpenCount:       len(issues),
+		OldestOpenIssue: oldestOpenIssueAge,
+	}, nil
+}
+
+// bbOpenIssuesQuery returns the BitBucket query matching open issues,
+// optionally filtered by kind and priority. Both must be validated first.
+func bbOpenIssuesQuery(kind, priority string) string {
+	query := `(state="new" OR state="open")`
+	if kind != "" {
+		query += ` AND kind="` + kind + `"`
+	}
+	if priority != "" {
+		query += ` AND priority="` + priority + `"`
+	}
+
+	return query
+}
+
+func (bb *BBCloud) queryBBIssues(ctx context.Context, request BadgeRequest, query string) ([]bbIssue, error) {
+	issues := []bbIssue{}
+	endpoint := "/issues?pagelen=100&q=" + url.QueryEscape(query)
+	err := bb.queryBBPages(ctx, request, endpoint, func(body []byte) (bool, error) {
+		var response bbIssuesResponse
+		err := json.Unmarshal(body, &response)
+		if err != nil {
+			log.Error("Answer decoding failed for:")
+			log.Error(body)
+			return false, err
+		}
+
+		issues = append(issues, response.Issues...)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return issues, nil
+}
@@ -0,0 +1,24 @@
+package bitbadger
+
+import (
+	"testing"
+)
+
+func TestBBOpenIssuesQuery(t *testing.T) {
+	cases := []struct {
+		kind     string
+		priority string
+		expected string
+	}{
+		{"", "", `(state="new" OR state="open")`},
+		{"bug", "", `(state="new" OR state="open") AND kind="bug"`},
+		{"bug", "major", `(state="new" OR state="open") AND kind="bug" AND priority="major"`},
+	}
+
+	for _, c := range cases {
+		query := bbOpenIssuesQuery(c.kind, c.priority)
+		if query != c.expected {
+			t.Errorf("bbOpenIssuesQuery: Expected '%s', got '%s'", c.expected, query)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package bitbadger
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+type bbTag struct {
+	Name   string   `json:"name"`
+	Date   string   `json:"date"`
+	Target bbCommit `json:"target"`
+}
+
+type bbTagsResponse struct {
+	Tags []bbTag `json:"values"`
+}
+
+// RetrieveTagsInfo retrieves information relative to tags from BitBucket
+// Cloud.
+func (bb *BBCloud) RetrieveTagsInfo(ctx context.Context, request BadgeRequest) (TagsInfo, error) {
+	tags, err := bb.queryBBTags(ctx, request)
+	if err != nil {
+		return TagsInfo{}, err
+	}
+
+	latestTag, found := findBBLatestTag(tags, request.TagPrefix, request.PreRelease)
+	if !found {
+		return TagsInfo{}, nil
+	}
+
+	tagsInfo := TagsInfo{
+		LatestTag: latestTag.Name,
+	}
+
+	// Annotated tags have their own date, lightweight tags only have the date
+	// of the commit they point to.
+	tagDate := latestTag.Date
+	if tagDate == "" {
+		tagDate = latestTag.Target.Date
+	}
+
+	tagTime, err := time.Parse(time.RFC3339, tagDate)
+	if err != nil {
+		log.Error("Failed to parse time:", tagDate)
+	} else {
+		tagsInfo.LatestTagAge = time.Since(tagTime)
+	}
+
+	return tagsInfo, nil
+}
+
+func (bb *BBCloud) queryBBTags(ctx context.Context, request BadgeRequest) ([]bbTag, error) {
+	tags := []bbTag{}
+	err := bb.queryBBPages(ctx, request, "/refs/tags?pagelen=100", func(body []byte) (bool, error) {
+		var response bbTagsResponse
+		err := json.Unmarshal(body, &response)
+		if err != nil {
+			log.Error("Answer decoding failed for:")
+			log.Error(body)
+			return false, err
+		}
+
+		tags = append(tags, response.Tags...)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// findBBLatestTag returns the tag with the highest semantic version, among
+// tags starting with prefix. Tags which are not semantic versions once the
+// prefix is removed are ignored, as well as pre-releases unless preRelease is
+// true.
+func findBBLatestTag(tags []bbTag, prefix string, preRelease bool) (bbTag, bool) {
+	var latestTag bbTag
+	var latestVersion semanticVersion
+	found := false
+
+	for _, tag := range tags {
+		if !strings.HasPrefix(tag.Name, prefix) {
+			continue
+		}
+
+		version, valid := parseSemanticVersion(strings.TrimPrefix(tag.Name, prefix))
+		if !valid || (version.IsPreRelease() && !preRelease) {
+			continue
+		}
+
+		if !found || version.Compare(latestVersion) > 0 {
+			latestTag = tag
+			latestVersion = version
+			found = true
+		}
+	}
+
+	return latestTag, found
+}
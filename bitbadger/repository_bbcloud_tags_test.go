@@ -0,0 +1,42 @@
+package bitbadger
+
+import (
+	"testing"
+)
+
+func TestFindBBLatestTag(t *testing.T) {
+	tags := []bbTag{
+		{Name: "v1.2.0"},
+		{Name: "v1.10.0"},
+		{Name: "v2.0.0-rc.1"},
+		{Name: "1.11.0"},
+		{Name: "api-3.0.0"},
+		{Name: "nightly"},
+	}
+
+	cases := []struct {
+		prefix     string
+		preRelease bool
+		expected   string
+		found      bool
+	}{
+		{"", false, "1.11.0", true},
+		{"", true, "v2.0.0-rc.1", true},
+		{"v", false, "v1.10.0", true},
+		{"v", true, "v2.0.0-rc.1", true},
+		{"api-", false, "api-3.0.0", true},
+		{"release-", false, "", false},
+	}
+
+	for _, c := range cases {
+		tag, found := findBBLatestTag(tags, c.prefix, c.preRelease)
+		if found != c.found {
+			t.Errorf("findBBLatestTag: Expected found to be %t for prefix '%s'", c.found, c.prefix)
+			continue
+		}
+		if found && tag.Name != c.expected {
+			t.Errorf("findBBLatestTag: Expected '%s' for prefix '%s' (pre-release %t), got '%s'",
+				c.expected, c.prefix, c.preRelease, tag.Name)
+		}
+	}
+}
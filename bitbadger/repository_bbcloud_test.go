@@ -0,0 +1,263 @@
+package bitbadger
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBBReviewState(t *testing.T) {
+	alice := bbUser{Nickname: "alice", AccountID: "1234:abcd", UUID: "{a-uuid}"}
+	bob := bbUser{Nickname: "bob"}
+
+	unreviewed := bbPullRequest{
+		Reviewers: []bbUser{alice, bob},
+		Participants: []bbParticipant{
+			{User: alice, Role: "REVIEWER"},
+			{User: bob, Role: "REVIEWER"},
+		},
+	}
+	approvedByBob := bbPullRequest{
+		Reviewers: []bbUser{alice, bob},
+		Participants: []bbParticipant{
+			{User: alice, Role: "REVIEWER"},
+			{User: bob, Role: "REVIEWER", Approved: true},
+		},
+	}
+	changesRequestedByAlice := bbPullRequest{
+		Reviewers: []bbUser{alice},
+		Participants: []bbParticipant{
+			{User: alice, Role: "REVIEWER", State: "changes_requested"},
+		},
+	}
+
+	if bbPullRequestReviewed(unreviewed) {
+		t.Errorf("bbPullRequestReviewed: PR without approval should not be reviewed")
+	}
+	if !bbPullRequestReviewed(approvedByBob) {
+		t.Errorf("bbPullRequestReviewed: Approved PR should be reviewed")
+	}
+	if !bbPullRequestReviewed(changesRequestedByAlice) {
+		t.Errorf("bbPullRequestReviewed: PR with changes requested should be reviewed")
+	}
+
+	cases := []struct {
+		pullRequest bbPullRequest
+		user        string
+		expected    bool
+	}{
+		{unreviewed, "alice", true},
+		{unreviewed, "1234:abcd", true},
+		{unreviewed, "a-uuid", true},
+		{unreviewed, "{a-uuid}", true},
+		{unreviewed, "carol", false},
+		{approvedByBob, "bob", false},
+		{approvedByBob, "alice", true},
+		{changesRequestedByAlice, "alice", false},
+		{changesRequestedByAlice, "bob", false},
+	}
+
+	for i, c := range cases {
+		if bbReviewPending(c.pullRequest, c.user) != c.expected {
+			t.Errorf("bbReviewPending: Expected %t for case %d (user '%s')", c.expected, i, c.user)
+		}
+	}
+}
+
+func TestBBCloudRetrieveRepositories(t *testing.T) {
+	var upstream *httptest.Server
+	upstream = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ := r.BasicAuth()
+		if username != "user" || password != "secret" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/team":
+			fmt.Fprintf(w, `{"values": [{"slug": "api"}], "next": "%s/team/page2"}`, upstream.URL)
+		case "/team/page2":
+			fmt.Fprint(w, `{"values": [{"slug": "web"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer upstream.Close()
+
+	bb := NewBBCloud(Config{Username: "user", Password: "secret"}, upstream.Client())
+	bb.apiURL = upstream.URL + "/"
+
+	repositories, err := bb.RetrieveRepositories(context.Background(), BadgeRequest{Username: "team"})
+	if err != nil {
+		t.Fatalf("RetrieveRepositories: Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(repositories, []string{"api", "web"}) {
+		t.Errorf("RetrieveRepositories: Unexpected repositories %v", repositories)
+	}
+}
+
+func TestBBCloudCallTimeout(t *testing.T) {
+	release := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer upstream.Close()
+	defer close(release)
+
+	bb := NewBBCloud(Config{Username: "user", Password: "secret"}, upstream.Client())
+	bb.apiURL = upstream.URL + "/"
+	bb.SetCallTimeout(50 * time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := bb.RetrieveRepositories(context.Background(), BadgeRequest{Username: "team"})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Errorf("RetrieveRepositories: Expected a timeout error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("RetrieveRepositories: The call should time out")
+	}
+}
+
+func TestBBCloudRetries(t *testing.T) {
+	cases := []struct {
+		statuses         []int
+		expectedRequests int
+		expectedKind     UpstreamErrorKind
+		expectedError    bool
+	}{
+		{[]int{http.StatusOK}, 1, 0, false},
+		{[]int{http.StatusServiceUnavailable, http.StatusOK}, 2, 0, false},
+		{[]int{http.StatusTooManyRequests, http.StatusOK}, 2, 0, false},
+		{[]int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}, 3, UpstreamServerError, true},
+		{[]int{http.StatusNotFound}, 1, UpstreamNotFound, true},
+		{[]int{http.StatusUnauthorized}, 1, UpstreamUnauthorized, true},
+	}
+
+	for _, c := range cases {
+		requests := 0
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			status := c.statuses[requests]
+			requests++
+			if status == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "0")
+			}
+			w.WriteHeader(status)
+			fmt.Fprint(w, `{"values": []}`)
+		}))
+
+		bb := NewBBCloud(Config{Username: "retries", Password: "secret"}, upstream.Client(),
+			WithBBRetryPolicy(RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Second}),
+			WithBBRateLimiter(NewRateLimiter(RateLimitPolicy{})))
+		bb.apiURL = upstream.URL + "/"
+
+		_, err := bb.RetrieveRepositories(context.Background(), BadgeRequest{Username: "team"})
+		upstream.Close()
+
+		if requests != c.expectedRequests {
+			t.Errorf("RetrieveRepositories: Expected %d requests for %v, got %d", c.expectedRequests, c.statuses, requests)
+		}
+		if (err != nil) != c.expectedError {
+			t.Errorf("RetrieveRepositories: Unexpected error '%v' for %v", err, c.statuses)
+		}
+		if upstreamErr, ok := err.(*UpstreamError); c.expectedError && (!ok || upstreamErr.Kind != c.expectedKind) {
+			t.Errorf("RetrieveRepositories: Expected a %s error for %v, got '%v'", c.expectedKind, c.statuses, err)
+		}
+	}
+}
+
+func TestBBEndpointName(t *testing.T) {
+	cases := []struct {
+		url      string
+		expected string
+	}{
+		{bbAPIURL + "team", "workspace"},
+		{bbAPIURL + "team?page=2", "workspace"},
+		{bbAPIURL + "user/repo", "repository"},
+		{bbAPIURL + "user/repo" + bbOpenPullRequestsEndpoint, "pullrequests"},
+		{bbAPIURL + "user/repo/pullrequests/12/activity", "pullrequests"},
+		{bbAPIURL + "user/repo/refs/branches/master", "refs/branches"},
+		{bbAPIURL + "user/repo/refs/tags?pagelen=100", "refs/tags"},
+		{bbAPIURL + "user/repo/commits/master?pagelen=100", "commits"},
+		{"https://api.bitbucket.org/2.0/user", "user"},
+	}
+
+	for _, c := range cases {
+		if name := bbEndpointName(bbAPIURL, c.url); name != c.expected {
+			t.Errorf("bbEndpointName: Expected '%s' for '%s', got '%s'", c.expected, c.url, name)
+		}
+	}
+}
+
+func TestBBCloudMergedPRHistory(t *testing.T) {
+	now := time.Now().UTC()
+	recent := now.Add(-24 * time.Hour).Format(time.RFC3339)
+	old := now.Add(-365 * 24 * time.Hour).Format(time.RFC3339)
+
+	var queries []string
+	var upstream *httptest.Server
+	upstream = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("state")+" "+r.URL.Query().Get("sort"))
+
+		switch {
+		case r.URL.Query().Get("state") == "OPEN":
+			fmt.Fprint(w, `{"values": []}`)
+		case r.URL.Query().Get("sort") == "" && r.URL.Query().Get("page") == "":
+			fmt.Fprintf(w, `{"values": [{"created_on": "%s", "updated_on": "%s"}], "next": "%s/team/repo/pullrequests?state=MERGED&page=2"}`,
+				now.Add(-26*time.Hour).Format(time.RFC3339), recent, upstream.URL)
+		case r.URL.Query().Get("page") == "":
+			fmt.Fprintf(w, `{"values": [{"created_on": "%s", "updated_on": "%s"}], "next": "%s/team/repo/pullrequests?state=MERGED&sort=-updated_on&page=2"}`,
+				old, recent, upstream.URL)
+		default:
+			fmt.Fprintf(w, `{"values": [{"created_on": "%s", "updated_on": "%s"}]}`, old, old)
+		}
+	}))
+	defer upstream.Close()
+
+	bb := NewBBCloud(Config{Username: "user", Password: "secret"}, upstream.Client(), WithBBRateLimiter(NewRateLimiter(RateLimitPolicy{})))
+	bb.apiURL = upstream.URL + "/"
+
+	cases := []struct {
+		badgeType       BadgeType
+		show            string
+		expectedQueries []string
+	}{
+		{OpenPRCountType, "", []string{"OPEN "}},
+		{AveragePRMergeTime, "", []string{"OPEN ", "MERGED "}},
+		{MergedPRsPerWeekType, "", []string{"OPEN ", "MERGED -updated_on", "MERGED -updated_on"}},
+		{SummaryType, "open-pr-count,build-status", []string{"OPEN "}},
+		{SummaryType, "", []string{"OPEN ", "MERGED "}},
+		{HealthType, "", []string{"OPEN ", "MERGED ", "MERGED -updated_on", "MERGED -updated_on"}},
+	}
+
+	for _, c := range cases {
+		queries = nil
+		prInfo, err := bb.RetrievePullRequestInfo(context.Background(), BadgeRequest{Username: "team", Repository: "repo", Type: c.badgeType, Show: c.show})
+		if err != nil {
+			t.Fatalf("RetrievePullRequestInfo: Unexpected error: %s", err)
+		}
+		if !reflect.DeepEqual(queries, c.expectedQueries) {
+			t.Errorf("RetrievePullRequestInfo: Expected queries %q for %s, got %q", c.expectedQueries, c.badgeType, queries)
+		}
+
+		mergeTime, throughput := mergedPRHistoryNeeds(BadgeRequest{Type: c.badgeType, Show: c.show})
+		if mergeTime && (prInfo.MergeTimeSamples != 1 || prInfo.AveragePRMergeTime != 2*time.Hour) {
+			t.Errorf("RetrievePullRequestInfo: Unexpected merge time for %s: %+v", c.badgeType, prInfo)
+		}
+		if throughput && prInfo.MergedCount != 1 {
+			t.Errorf("RetrievePullRequestInfo: Unexpected merged count for %s: %+v", c.badgeType, prInfo)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package bitbadger
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+type bbRepositoriesResponse struct {
+	Repositories []struct {
+		Slug string `json:"slug"`
+	} `json:"values"`
+}
+
+// RetrieveRepositories retrieves the slugs of the repositories of the
+// request workspace from BitBucket Cloud, restricted to the request project
+// if any.
+func (bb *BBCloud) RetrieveRepositories(ctx context.Context, request BadgeRequest) ([]string, error) {
+	repositoriesURL := bb.apiURL + url.PathEscape(request.Username) + "?pagelen=100"
+	if request.Project != "" {
+		repositoriesURL += "&q=" + url.QueryEscape(`project.key="`+request.Project+`"`)
+	}
+
+	repositories := []string{}
+	err := bb.queryBBURLPages(ctx, repositoriesURL, func(body []byte) (bool, error) {
+		var response bbRepositoriesResponse
+		err := json.Unmarshal(body, &response)
+		if err != nil {
+			log.Error("Answer decoding failed for:")
+			log.Error(body)
+			return false, err
+		}
+
+		for _, repository := range response.Repositories {
+			repositories = append(repositories, repository.Slug)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return repositories, nil
+}
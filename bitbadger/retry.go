@@ -0,0 +1,90 @@
+package bitbadger
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy holds how requests failing with a temporary upstream error are
+// retried.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt. Zero
+	// disables retries.
+	MaxRetries int
+	// BaseDelay is the delay before the first retry, doubled on each retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between two attempts. Requests are not retried
+	// if the upstream server asks to wait longer.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy used unless configured otherwise.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   30 * time.Second,
+}
+
+var retryPolicy = DefaultRetryPolicy
+
+// SetRetryPolicy sets the global retry policy, used by the package level
+// functions. Servers and providers use the policy of WithRetryPolicy and
+// WithBBRetryPolicy.
+func SetRetryPolicy(policy RetryPolicy) {
+	retryPolicy = policy
+}
+
+// GetRetryPolicy returns the current global retry policy.
+func GetRetryPolicy() RetryPolicy {
+	return retryPolicy
+}
+
+// backoff returns the delay before a retry, growing exponentially with the
+// number of previous retries. A random jitter spreads the retries of
+// concurrent requests.
+func (policy RetryPolicy) backoff(retries int) time.Duration {
+	delay := policy.BaseDelay
+	for i := 0; i < retries && delay < policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > policy.MaxDelay {
+		delay = policy.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// retryDelay returns how long to wait before retrying a request which failed
+// with err, or false if it must not be retried.
+func (policy RetryPolicy) retryDelay(err error, retries int) (time.Duration, bool) {
+	upstreamErr, ok := err.(*UpstreamError)
+	if !ok || !upstreamErr.Temporary() || retries >= policy.MaxRetries {
+		return 0, false
+	}
+
+	if upstreamErr.RetryAfter > 0 {
+		if upstreamErr.RetryAfter > policy.MaxDelay {
+			return 0, false
+		}
+		return upstreamErr.RetryAfter, true
+	}
+
+	return policy.backoff(retries), true
+}
+
+// sleep waits for delay, or until ctx is done.
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,55 @@
+package bitbadger
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 5, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+
+	cases := []struct {
+		retries int
+		max     time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{3, 5 * time.Second},
+		{10, 5 * time.Second},
+	}
+
+	for _, c := range cases {
+		for i := 0; i < 20; i++ {
+			delay := policy.backoff(c.retries)
+			if delay < c.max/2 || delay > c.max {
+				t.Errorf("backoff: Expected a delay within [%v, %v] after %d retries, got %v", c.max/2, c.max, c.retries, delay)
+			}
+		}
+	}
+}
+
+func TestRetryPolicyRetryDelay(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 2, BaseDelay: time.Second, MaxDelay: time.Minute}
+
+	cases := []struct {
+		err      error
+		retries  int
+		retry    bool
+		expected time.Duration
+	}{
+		{errors.New("Connection refused"), 0, false, 0},
+		{&UpstreamError{Kind: UpstreamNotFound, StatusCode: 404}, 0, false, 0},
+		{&UpstreamError{Kind: UpstreamServerError, StatusCode: 503}, 2, false, 0},
+		{&UpstreamError{Kind: UpstreamRateLimited, StatusCode: 429, RetryAfter: 10 * time.Second}, 0, true, 10 * time.Second},
+		{&UpstreamError{Kind: UpstreamRateLimited, StatusCode: 429, RetryAfter: time.Hour}, 0, false, 0},
+	}
+
+	for _, c := range cases {
+		delay, retry := policy.retryDelay(c.err, c.retries)
+		if retry != c.retry || delay != c.expected {
+			t.Errorf("retryDelay: Expected %v, %v for '%s', got %v, %v", c.expected, c.retry, c.err, delay, retry)
+		}
+	}
+}
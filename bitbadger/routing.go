@@ -0,0 +1,176 @@
+package bitbadger
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+)
+
+// ErrNoCredentials is returned for requests whose workspace matches no
+// credential route, see WithCredentialRoutes.
+var ErrNoCredentials = errors.New("No credentials for this workspace")
+
+// CredentialRoute maps workspaces to the provider serving their badges, such
+// as a BitBucket Cloud provider authenticating with an account which can see
+// them.
+type CredentialRoute struct {
+	// Workspace is a pattern matching the workspace of requests, such as
+	// "acme-*", see path.Match. Empty matches all workspaces.
+	Workspace string
+	// Project is a pattern matching the project selected by workspace
+	// badges. Empty matches all requests.
+	Project string
+	// Provider serves the matching requests.
+	Provider Provider
+}
+
+// ValidateCredentialRoute returns an error if the patterns of route are
+// malformed.
+func ValidateCredentialRoute(route CredentialRoute) error {
+	for _, pattern := range []string{route.Workspace, route.Project} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errors.New("Invalid pattern '" + pattern + "': " + err.Error())
+		}
+	}
+
+	return nil
+}
+
+// matches returns true if request matches the patterns of route.
+func (route CredentialRoute) matches(request BadgeRequest) bool {
+	return matchPattern(route.Workspace, request.Username) && matchPattern(route.Project, request.Project)
+}
+
+// patterns returns the patterns of route, such as "acme-*/WEB", or an empty
+// string if it matches all requests.
+func (route CredentialRoute) patterns() string {
+	switch {
+	case route.Project != "":
+		workspace := route.Workspace
+		if workspace == "" {
+			workspace = "*"
+		}
+		return workspace + "/" + route.Project
+	default:
+		return route.Workspace
+	}
+}
+
+// matchPattern returns true if value matches pattern, or if pattern is
+// empty.
+func matchPattern(pattern string, value string) bool {
+	if pattern == "" {
+		return true
+	}
+
+	matched, _ := path.Match(pattern, value)
+	return matched
+}
+
+// providerRoute is a credential route whose provider is guarded by its own
+// circuit breaker, so that the outage of an account doesn't stop the others.
+type providerRoute struct {
+	CredentialRoute
+	guarded *circuitBreakerProvider
+}
+
+func newProviderRoute(route CredentialRoute, policy CircuitBreakerPolicy) *providerRoute {
+	name := providerName(route.Provider)
+	if patterns := route.patterns(); patterns != "" {
+		name += " " + patterns
+	}
+
+	return &providerRoute{
+		CredentialRoute: route,
+		guarded: &circuitBreakerProvider{
+			provider: route.Provider,
+			breaker:  NewCircuitBreaker(name, policy),
+		},
+	}
+}
+
+// checker returns the readiness checker of the route. Routes of a single
+// workspace check that the provider can serve it, if it supports it, rather
+// than checking the provider credentials in general.
+func (route *providerRoute) checker() Checker {
+	if checker, ok := route.Provider.(workspaceChecker); ok && route.Workspace != "" &&
+		!strings.ContainsAny(route.Workspace, `*?[\`) {
+		return checkerFunc(func(ctx context.Context) error {
+			return checker.CheckWorkspace(ctx, route.Workspace)
+		})
+	}
+
+	return route.guarded
+}
+
+// routingProvider sends each request to the provider of the first route it
+// matches. Requests matching no route fail with ErrNoCredentials.
+type routingProvider []*providerRoute
+
+// route returns the route of request, or nil if it matches none.
+func (routes routingProvider) route(request BadgeRequest) *providerRoute {
+	for _, route := range routes {
+		if route.matches(request) {
+			return route
+		}
+	}
+
+	return nil
+}
+
+func (routes routingProvider) RetrievePullRequestInfo(ctx context.Context, request BadgeRequest) (PullRequestsInfo, error) {
+	route := routes.route(request)
+	if route == nil {
+		return PullRequestsInfo{}, ErrNoCredentials
+	}
+	return route.guarded.RetrievePullRequestInfo(ctx, request)
+}
+
+func (routes routingProvider) RetrieveBuildInfo(ctx context.Context, request BadgeRequest) (BuildInfo, error) {
+	route := routes.route(request)
+	if route == nil {
+		return BuildInfo{}, ErrNoCredentials
+	}
+	return route.guarded.RetrieveBuildInfo(ctx, request)
+}
+
+func (routes routingProvider) RetrieveCommitsInfo(ctx context.Context, request BadgeRequest) (CommitsInfo, error) {
+	route := routes.route(request)
+	if route == nil {
+		return CommitsInfo{}, ErrNoCredentials
+	}
+	return route.guarded.RetrieveCommitsInfo(ctx, request)
+}
+
+func (routes routingProvider) RetrieveBranchesInfo(ctx context.Context, request BadgeRequest) (BranchesInfo, error) {
+	route := routes.route(request)
+	if route == nil {
+		return BranchesInfo{}, ErrNoCredentials
+	}
+	return route.guarded.RetrieveBranchesInfo(ctx, request)
+}
+
+func (routes routingProvider) RetrieveIssuesInfo(ctx context.Context, request BadgeRequest) (IssuesInfo, error) {
+	route := routes.route(request)
+	if route == nil {
+		return IssuesInfo{}, ErrNoCredentials
+	}
+	return route.guarded.RetrieveIssuesInfo(ctx, request)
+}
+
+func (routes routingProvider) RetrieveTagsInfo(ctx context.Context, request BadgeRequest) (TagsInfo, error) {
+	route := routes.route(request)
+	if route == nil {
+		return TagsInfo{}, ErrNoCredentials
+	}
+	return route.guarded.RetrieveTagsInfo(ctx, request)
+}
+
+func (routes routingProvider) RetrieveRepositories(ctx context.Context, request BadgeRequest) ([]string, error) {
+	route := routes.route(request)
+	if route == nil {
+		return nil, ErrNoCredentials
+	}
+	return route.guarded.RetrieveRepositories(ctx, request)
+}
@@ -0,0 +1,128 @@
+package bitbadger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCredentialRouteMatches(t *testing.T) {
+	cases := []struct {
+		route    CredentialRoute
+		request  BadgeRequest
+		expected bool
+	}{
+		{CredentialRoute{}, BadgeRequest{Username: "acme"}, true},
+		{CredentialRoute{Workspace: "acme"}, BadgeRequest{Username: "acme"}, true},
+		{CredentialRoute{Workspace: "acme"}, BadgeRequest{Username: "acme-labs"}, false},
+		{CredentialRoute{Workspace: "acme-*"}, BadgeRequest{Username: "acme-labs"}, true},
+		{CredentialRoute{Workspace: "acme", Project: "WEB"}, BadgeRequest{Username: "acme", Project: "WEB"}, true},
+		{CredentialRoute{Workspace: "acme", Project: "WEB"}, BadgeRequest{Username: "acme"}, false},
+		{CredentialRoute{Project: "WEB*"}, BadgeRequest{Username: "acme", Project: "WEBAPP"}, true},
+	}
+
+	for _, c := range cases {
+		if matches := c.route.matches(c.request); matches != c.expected {
+			t.Errorf("matches: Expected %v for %+v and %s/%s", c.expected, c.route, c.request.Username, c.request.Project)
+		}
+	}
+}
+
+func TestValidateCredentialRoute(t *testing.T) {
+	if err := ValidateCredentialRoute(CredentialRoute{Workspace: "acme-*", Project: "WEB"}); err != nil {
+		t.Errorf("ValidateCredentialRoute: Unexpected error: %s", err)
+	}
+
+	invalidRoutes := []CredentialRoute{
+		{Workspace: "acme-["},
+		{Workspace: "acme", Project: "[WEB"},
+	}
+	for _, route := range invalidRoutes {
+		if err := ValidateCredentialRoute(route); err == nil {
+			t.Errorf("ValidateCredentialRoute: Should generate an error for %+v", route)
+		}
+	}
+}
+
+func TestServerCredentialRoutes(t *testing.T) {
+	acme := &fakeProvider{openCount: 3}
+	web := &fakeProvider{openCount: 5}
+	fallback := &fakeProvider{openCount: 12}
+
+	routes := []CredentialRoute{
+		{Workspace: "acme", Project: "WEB", Provider: web},
+		{Workspace: "acme*", Provider: acme},
+	}
+	routedServer := NewServer(WithCredentialRoutes(routes...), WithRenderer(LocalRenderer{}))
+	fallbackServer := NewServer(WithCredentialRoutes(routes...), WithProvider(fallback), WithRenderer(LocalRenderer{}))
+
+	cases := []struct {
+		server          *Server
+		url             string
+		expectedStatus  int
+		expectedContent string
+	}{
+		{routedServer, "/acme/repo/open-pr-count", http.StatusOK, ">3</text>"},
+		{routedServer, "/acme-labs/repo/open-pr-count", http.StatusOK, ">3</text>"},
+		{routedServer, "/acme/*/open-pr-count?project=WEB", http.StatusOK, ">10</text>"},
+		{routedServer, "/other/repo/open-pr-count", http.StatusOK, ">no credentials for this workspace</text>"},
+		{routedServer, "/other/repo/open-pr-count?lang=fr", http.StatusOK, ">aucun identifiant pour cet espace de travail</text>"},
+		{routedServer, "/other/repo/open-pr-count.json", http.StatusForbidden, "No credentials"},
+		{routedServer, "/other/*/open-pr-count", http.StatusOK, ">no credentials for this workspace</text>"},
+		{fallbackServer, "/acme/repo/open-pr-count", http.StatusOK, ">3</text>"},
+		{fallbackServer, "/other/repo/open-pr-count", http.StatusOK, ">12</text>"},
+	}
+
+	for _, c := range cases {
+		recorder := httptest.NewRecorder()
+		c.server.ServeHTTP(recorder, httptest.NewRequest("GET", c.url, nil))
+
+		if recorder.Code != c.expectedStatus {
+			t.Errorf("ServeHTTP: Expected status %d for '%s', got %d", c.expectedStatus, c.url, recorder.Code)
+		}
+		if !strings.Contains(recorder.Body.String(), c.expectedContent) {
+			t.Errorf("ServeHTTP: Expected '%s' in the response to '%s', got '%s'", c.expectedContent, c.url, recorder.Body.String())
+		}
+	}
+
+	// Missing credentials are not cached.
+	if routedServer.cache.Cached(BadgeRequest{Username: "other", Repository: "repo", Type: OpenPRCountType}) {
+		t.Errorf("ServeHTTP: Missing credentials should not be cached")
+	}
+
+	// Each route has its own circuit breaker.
+	status := fallbackServer.Status()
+	if len(status.Providers) != 3 {
+		t.Fatalf("Status: Expected 3 circuit breakers, got %+v", status.Providers)
+	}
+	if status.Providers[0].Name != "provider acme/WEB" || status.Providers[1].Name != "provider acme*" || status.Providers[2].Name != "provider" {
+		t.Errorf("Status: Unexpected circuit breakers %+v", status.Providers)
+	}
+}
+
+func TestServerCredentialRoutesCircuitOpen(t *testing.T) {
+	failing := &fakeProvider{err: &UpstreamError{Kind: UpstreamServerError, StatusCode: 503}}
+	working := &fakeProvider{openCount: 3}
+	server := NewServer(
+		WithCredentialRoutes(CredentialRoute{Workspace: "failing", Provider: failing}),
+		WithProvider(working),
+		WithRenderer(LocalRenderer{}),
+		WithCircuitBreakerPolicy(CircuitBreakerPolicy{FailureThreshold: 1, OpenDuration: time.Minute}))
+
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/failing/repo/open-pr-count", nil))
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest("GET", "/failing/repo/open-pr-count", nil))
+	if !strings.Contains(recorder.Body.String(), ">unavailable</text>") || recorder.Header().Get("Retry-After") == "" {
+		t.Errorf("ServeHTTP: Expected an unavailable badge, got '%s'", recorder.Body.String())
+	}
+
+	// The outage of an account doesn't stop the others.
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest("GET", "/working/repo/open-pr-count", nil))
+	if !strings.Contains(recorder.Body.String(), ">3</text>") {
+		t.Errorf("ServeHTTP: Expected the other route to be served, got '%s'", recorder.Body.String())
+	}
+}
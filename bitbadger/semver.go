@@ -0,0 +1,120 @@
+package bitbadger
+
+import (
+	"strconv"
+	"strings"
+)
+
+// semanticVersion holds a parsed semantic version, see https://semver.org.
+// Build metadata is ignored as it does not affect precedence.
+type semanticVersion struct {
+	Major      int
+	Minor      int
+	Patch      int
+	PreRelease []string
+}
+
+// parseSemanticVersion parses a version of the form
+// "[v]MAJOR[.MINOR[.PATCH]][-PRERELEASE][+BUILD]". Missing minor and patch
+// numbers default to 0. It returns false if version is not valid.
+func parseSemanticVersion(version string) (semanticVersion, bool) {
+	version = strings.TrimPrefix(version, "v")
+
+	if i := strings.Index(version, "+"); i >= 0 {
+		version = version[:i]
+	}
+
+	var parsed semanticVersion
+	if i := strings.Index(version, "-"); i >= 0 {
+		parsed.PreRelease = strings.Split(version[i+1:], ".")
+		version = version[:i]
+
+		for _, identifier := range parsed.PreRelease {
+			if identifier == "" {
+				return semanticVersion{}, false
+			}
+		}
+	}
+
+	numbers := strings.Split(version, ".")
+	if len(numbers) > 3 {
+		return semanticVersion{}, false
+	}
+
+	components := []*int{&parsed.Major, &parsed.Minor, &parsed.Patch}
+	for i, number := range numbers {
+		value, err := strconv.Atoi(number)
+		if err != nil || value < 0 {
+			return semanticVersion{}, false
+		}
+		*components[i] = value
+	}
+
+	return parsed, true
+}
+
+// IsPreRelease returns true if the version is a pre-release.
+func (v semanticVersion) IsPreRelease() bool {
+	return len(v.PreRelease) > 0
+}
+
+// Compare returns -1, 0 or 1 if v has a lower, equal or higher precedence
+// than other.
+func (v semanticVersion) Compare(other semanticVersion) int {
+	if result := compareInts(v.Major, other.Major); result != 0 {
+		return result
+	}
+	if result := compareInts(v.Minor, other.Minor); result != 0 {
+		return result
+	}
+	if result := compareInts(v.Patch, other.Patch); result != 0 {
+		return result
+	}
+
+	// A pre-release has a lower precedence than the associated release.
+	switch {
+	case !v.IsPreRelease() && !other.IsPreRelease():
+		return 0
+	case !v.IsPreRelease():
+		return 1
+	case !other.IsPreRelease():
+		return -1
+	}
+
+	for i := 0; i < len(v.PreRelease) && i < len(other.PreRelease); i++ {
+		if result := comparePreReleaseIdentifiers(v.PreRelease[i], other.PreRelease[i]); result != 0 {
+			return result
+		}
+	}
+
+	return compareInts(len(v.PreRelease), len(other.PreRelease))
+}
+
+// comparePreReleaseIdentifiers compares numeric identifiers numerically, and
+// others lexically. Numeric identifiers have a lower precedence.
+func comparePreReleaseIdentifiers(a, b string) int {
+	aNumber, aErr := strconv.Atoi(a)
+	bNumber, bErr := strconv.Atoi(b)
+
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareInts(aNumber, bNumber)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,63 @@
+package bitbadger
+
+import (
+	"testing"
+)
+
+func TestParseSemanticVersion(t *testing.T) {
+	cases := []struct {
+		in       string
+		valid    bool
+		expected semanticVersion
+	}{
+		{"1.2.3", true, semanticVersion{Major: 1, Minor: 2, Patch: 3}},
+		{"v1.2.3", true, semanticVersion{Major: 1, Minor: 2, Patch: 3}},
+		{"2.0", true, semanticVersion{Major: 2}},
+		{"1.0.0-rc.1+build.5", true, semanticVersion{Major: 1, PreRelease: []string{"rc", "1"}}},
+		{"1.2.3.4", false, semanticVersion{}},
+		{"release-1", false, semanticVersion{}},
+		{"1.0.0-", false, semanticVersion{}},
+		{"", false, semanticVersion{}},
+	}
+
+	for _, c := range cases {
+		version, valid := parseSemanticVersion(c.in)
+		if valid != c.valid {
+			t.Errorf("parseSemanticVersion: Expected validity %t for '%s'", c.valid, c.in)
+			continue
+		}
+		if valid && version.Compare(c.expected) != 0 {
+			t.Errorf("parseSemanticVersion: Expected %+v for '%s', got %+v", c.expected, c.in, version)
+		}
+	}
+}
+
+func TestSemanticVersionCompare(t *testing.T) {
+	// Ordered by increasing precedence, as in the semver.org specification.
+	versions := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.2.0",
+		"1.10.0",
+		"2.0.0",
+	}
+
+	for i := 0; i < len(versions)-1; i++ {
+		lower, _ := parseSemanticVersion(versions[i])
+		higher, _ := parseSemanticVersion(versions[i+1])
+
+		if lower.Compare(higher) != -1 || higher.Compare(lower) != 1 {
+			t.Errorf("Compare: Expected '%s' < '%s'", versions[i], versions[i+1])
+		}
+		if lower.Compare(lower) != 0 {
+			t.Errorf("Compare: Expected '%s' == '%s'", versions[i], versions[i])
+		}
+	}
+}
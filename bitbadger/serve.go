@@ -0,0 +1,190 @@
+package bitbadger
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// DefaultDrainTimeout is how long servers wait for pending requests when
+// shutting down, unless set with WithDrainTimeout.
+const DefaultDrainTimeout = 10 * time.Second
+
+// ShutdownHook is called once a server stopped serving requests, for instance
+// to flush a persistent cache or stop background refreshers. The context
+// expires after the drain timeout.
+type ShutdownHook func(ctx context.Context) error
+
+// OnShutdown registers a hook called when the server stops serving, whether it
+// shut down or failed. Hooks are called in registration order, and must be
+// registered before serving.
+func (server *Server) OnShutdown(hook ShutdownHook) {
+	server.hooks = append(server.hooks, hook)
+}
+
+// ListenAndServe serves badges over HTTP on addr until ctx is done, and then
+// shuts down gracefully. It returns nil once shut down, or the error which
+// stopped the server.
+func (server *Server) ListenAndServe(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		server.shutdown()
+		return err
+	}
+
+	return server.Serve(ctx, listener)
+}
+
+// ListenAndServeTLS is similar to ListenAndServe, serving badges over HTTPS.
+func (server *Server) ListenAndServeTLS(ctx context.Context, addr, certFile, keyFile string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		server.shutdown()
+		return err
+	}
+
+	return server.ServeTLS(ctx, listener, certFile, keyFile)
+}
+
+// Serve serves badges over HTTP on listener until ctx is done, and then shuts
+// down gracefully. The listener is closed when Serve returns.
+func (server *Server) Serve(ctx context.Context, listener net.Listener) error {
+	httpServer := &http.Server{Handler: server}
+	return server.serve(ctx, httpServer, func() error {
+		return httpServer.Serve(listener)
+	})
+}
+
+// ServeTLS is similar to Serve, serving badges over HTTPS.
+func (server *Server) ServeTLS(ctx context.Context, listener net.Listener, certFile, keyFile string) error {
+	httpServer := &http.Server{Handler: server}
+	return server.serve(ctx, httpServer, func() error {
+		return httpServer.ServeTLS(listener, certFile, keyFile)
+	})
+}
+
+// serve runs listen, and the admin server if any, until either fails or ctx
+// is done. Pending requests are then drained, and shutdown hooks are called.
+func (server *Server) serve(ctx context.Context, httpServer *http.Server, listen func() error) error {
+	listenErrors := make(chan error, 2)
+	go func() {
+		listenErrors <- listen()
+	}()
+	httpServers := []*http.Server{httpServer}
+
+	if server.adminAddr != "" {
+		adminServer, err := server.listenAdmin(listenErrors)
+		if err != nil {
+			httpServer.Close()
+			<-listenErrors
+			server.shutdown()
+			return err
+		}
+		httpServers = append(httpServers, adminServer)
+	}
+
+	select {
+	case err := <-listenErrors:
+		// A server failed, there is nothing to drain.
+		for _, s := range httpServers {
+			s.Close()
+		}
+		for i := 1; i < len(httpServers); i++ {
+			<-listenErrors
+		}
+		server.shutdown()
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Info("Shutting down, waiting up to ", server.drainTimeout, " for pending requests")
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), server.drainTimeout)
+	defer cancel()
+
+	var err error
+	for _, s := range httpServers {
+		shutdownErr := s.Shutdown(drainCtx)
+		if shutdownErr != nil {
+			log.Error("Failed to drain pending requests: ", shutdownErr)
+			if err == nil {
+				err = shutdownErr
+			}
+		}
+	}
+
+	// Once shut down, servers return http.ErrServerClosed.
+	for range httpServers {
+		<-listenErrors
+	}
+
+	hooksErr := server.shutdown()
+	if err != nil {
+		return err
+	}
+
+	return hooksErr
+}
+
+// listenAdmin serves the admin endpoints on the admin address. The error
+// which stops the admin server is sent to listenErrors.
+func (server *Server) listenAdmin(listenErrors chan<- error) (*http.Server, error) {
+	listener, err := net.Listen("tcp", server.adminAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	adminServer := &http.Server{Handler: server.AdminHandler()}
+	go func() {
+		listenErrors <- adminServer.Serve(listener)
+	}()
+
+	return adminServer, nil
+}
+
+// shutdown calls the shutdown hooks, and returns the first error.
+func (server *Server) shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), server.drainTimeout)
+	defer cancel()
+
+	var firstErr error
+	for _, hook := range server.hooks {
+		err := hook(ctx)
+		if err != nil {
+			log.Error("Shutdown hook failed: ", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return firstErr
+}
+
+// ServeWithHTTP starts the HTTP bitbadger server on the specificed port,
+// using the global configuration.
+func ServeWithHTTP(port int) error {
+	return ServeWithHTTPContext(context.Background(), port)
+}
+
+// ServeWithHTTPS starts the HTTPS bitbadger server on the specificed port,
+// using the global configuration.
+func ServeWithHTTPS(port int, certFile, keyFile string) error {
+	return ServeWithHTTPSContext(context.Background(), port, certFile, keyFile)
+}
+
+// ServeWithHTTPContext is similar to ServeWithHTTP, shutting down gracefully
+// when ctx is done.
+func ServeWithHTTPContext(ctx context.Context, port int) error {
+	return defaultServer().ListenAndServe(ctx, ":"+strconv.Itoa(port))
+}
+
+// ServeWithHTTPSContext is similar to ServeWithHTTPS, shutting down
+// gracefully when ctx is done.
+func ServeWithHTTPSContext(ctx context.Context, port int, certFile, keyFile string) error {
+	return defaultServer().ListenAndServeTLS(ctx, ":"+strconv.Itoa(port), certFile, keyFile)
+}
@@ -0,0 +1,170 @@
+package bitbadger
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// slowProvider delays pull request information, to keep requests pending.
+type slowProvider struct {
+	fakeProvider
+	delay time.Duration
+}
+
+func (provider *slowProvider) RetrievePullRequestInfo(ctx context.Context, request BadgeRequest) (PullRequestsInfo, error) {
+	select {
+	case <-time.After(provider.delay):
+	case <-ctx.Done():
+		return PullRequestsInfo{}, ctx.Err()
+	}
+
+	return provider.fakeProvider.RetrievePullRequestInfo(ctx, request)
+}
+
+func TestServerGracefulShutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewServer(
+		WithProvider(&slowProvider{fakeProvider{openCount: 3}, 200 * time.Millisecond}),
+		WithRenderer(LocalRenderer{}),
+		WithDrainTimeout(5*time.Second))
+
+	hookCalls := 0
+	server.OnShutdown(func(ctx context.Context) error {
+		hookCalls++
+		return nil
+	})
+	server.OnShutdown(func(ctx context.Context) error {
+		hookCalls++
+		return errors.New("Flush failed")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	serveErrors := make(chan error, 1)
+	go func() {
+		serveErrors <- server.Serve(ctx, listener)
+	}()
+
+	responses := make(chan int, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/user/repo/open-pr-count")
+		if err != nil {
+			responses <- 0
+			return
+		}
+		defer resp.Body.Close()
+		ioutil.ReadAll(resp.Body)
+		responses <- resp.StatusCode
+	}()
+
+	// Shut down while the request is pending.
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	if status := <-responses; status != http.StatusOK {
+		t.Errorf("Serve: Pending requests should complete, got status %d", status)
+	}
+
+	err = <-serveErrors
+	if err == nil || err.Error() != "Flush failed" {
+		t.Errorf("Serve: Expected the shutdown hook error, got %v", err)
+	}
+	if hookCalls != 2 {
+		t.Errorf("Serve: Expected 2 shutdown hook calls, got %d", hookCalls)
+	}
+
+	_, err = http.Get("http://" + listener.Addr().String() + "/user/repo/open-pr-count")
+	if err == nil {
+		t.Errorf("Serve: Should not accept requests once shut down")
+	}
+}
+
+func TestServerListenError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	hookCalled := false
+	server := NewServer()
+	server.OnShutdown(func(ctx context.Context) error {
+		hookCalled = true
+		return nil
+	})
+
+	// The address is already in use.
+	err = server.ListenAndServe(context.Background(), listener.Addr().String())
+	if err == nil {
+		t.Errorf("ListenAndServe: Should return an error if the address is in use")
+	}
+	if !hookCalled {
+		t.Errorf("ListenAndServe: Hooks should be called if the server fails")
+	}
+}
+
+func TestServerAdminAddr(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reserve a free address for the admin endpoints.
+	adminListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	adminAddr := adminListener.Addr().String()
+	adminListener.Close()
+
+	server := NewServer(
+		WithProvider(&fakeProvider{openCount: 3}),
+		WithRenderer(LocalRenderer{}),
+		WithAdminAddr(adminAddr))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	serveErrors := make(chan error, 1)
+	go func() {
+		serveErrors <- server.Serve(ctx, listener)
+	}()
+
+	get := func(url string) int {
+		for i := 0; i < 50; i++ {
+			resp, err := http.Get(url)
+			if err == nil {
+				resp.Body.Close()
+				return resp.StatusCode
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		return 0
+	}
+
+	cases := []struct {
+		url            string
+		expectedStatus int
+	}{
+		{"http://" + adminAddr + MetricsPath, http.StatusOK},
+		{"http://" + adminAddr + StatusPath, http.StatusOK},
+		{"http://" + adminAddr + "/user/repo/open-pr-count", http.StatusNotFound},
+		{"http://" + listener.Addr().String() + MetricsPath, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		if status := get(c.url); status != c.expectedStatus {
+			t.Errorf("Serve: Expected status %d for '%s', got %d", c.expectedStatus, c.url, status)
+		}
+	}
+
+	cancel()
+	if err := <-serveErrors; err != nil {
+		t.Errorf("Serve: Unexpected error: %s", err)
+	}
+}
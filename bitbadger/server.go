@@ -0,0 +1,728 @@
+package bitbadger
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+type serverError struct {
+	Message         string
+	HTTPErrorStatus int
+}
+
+var client = &http.Client{}
+
+// Server serves badges over HTTP. It implements http.Handler, and is created
+// with NewServer.
+type Server struct {
+	config       Config
+	client       *http.Client
+	cache        *Cache
+	provider     Provider
+	renderer     Renderer
+	drainTimeout time.Duration
+	hooks        []ShutdownHook
+
+	callTimeout    time.Duration
+	requestTimeout time.Duration
+
+	credentialRoutes []CredentialRoute
+	routes           routingProvider
+
+	adminAddr       string
+	readinessChecks []*readinessCheck
+
+	retryPolicy        RetryPolicy
+	rateLimiter        *RateLimiter
+	breakerPolicy      CircuitBreakerPolicy
+	draftPolicy        DraftPolicy
+	throughputPolicy   ThroughputPolicy
+	staleBranchPolicy  StaleBranchPolicy
+	contributorsPolicy ContributorsPolicy
+	workspacePolicy    WorkspacePolicy
+	healthPolicy       HealthPolicy
+	businessHours      BusinessHoursPolicy
+	durationFormat     string
+	factory            badgeFactory
+}
+
+// Default timeouts of servers, see WithCallTimeout and WithRequestTimeout.
+const (
+	DefaultCallTimeout    = 10 * time.Second
+	DefaultRequestTimeout = 30 * time.Second
+)
+
+// ServerOption configures a Server, see NewServer.
+type ServerOption func(server *Server)
+
+// WithConfig sets the configuration used to authenticate to the upstream
+// repository. It is ignored if a provider is set with WithProvider.
+func WithConfig(config Config) ServerOption {
+	return func(server *Server) {
+		server.config = config
+	}
+}
+
+// WithHTTPClient sets the client used to send requests to the upstream
+// repository and to shields.io. It is ignored by providers and renderers set
+// with WithProvider and WithRenderer.
+func WithHTTPClient(client *http.Client) ServerOption {
+	return func(server *Server) {
+		server.client = client
+	}
+}
+
+// WithCache sets the cache of badge results. Servers can share a cache, as
+// long as their providers return the same results for the same requests.
+func WithCache(cache *Cache) ServerOption {
+	return func(server *Server) {
+		server.cache = cache
+	}
+}
+
+// WithProvider sets the provider retrieving the upstream data of badges,
+// BitBucket Cloud by default. The provider is guarded by a circuit breaker,
+// named after its Name() method if it has one.
+func WithProvider(provider Provider) ServerOption {
+	return func(server *Server) {
+		server.provider = provider
+	}
+}
+
+// WithCredentialRoutes serves the requests matching a route with its
+// provider, the first matching route taking precedence. Requests matching no
+// route are served by the provider set with WithConfig or WithProvider, if
+// any, or else fail with a "no credentials" badge. Each provider is guarded by
+// its own circuit breaker. Route patterns must be valid, see
+// ValidateCredentialRoute.
+func WithCredentialRoutes(routes ...CredentialRoute) ServerOption {
+	return func(server *Server) {
+		server.credentialRoutes = append(server.credentialRoutes, routes...)
+	}
+}
+
+// WithRenderer sets the renderer of badge images, shields.io by default.
+func WithRenderer(renderer Renderer) ServerOption {
+	return func(server *Server) {
+		server.renderer = renderer
+	}
+}
+
+// WithDrainTimeout sets how long the server waits for pending requests, and
+// then for shutdown hooks, when shutting down. Defaults to
+// DefaultDrainTimeout.
+func WithDrainTimeout(timeout time.Duration) ServerOption {
+	return func(server *Server) {
+		server.drainTimeout = timeout
+	}
+}
+
+// WithCallTimeout sets the maximum duration of each request to the upstream
+// repository and to shields.io. It is ignored by providers and renderers set
+// with WithProvider and WithRenderer. Zero means no timeout.
+func WithCallTimeout(timeout time.Duration) ServerOption {
+	return func(server *Server) {
+		server.callTimeout = timeout
+	}
+}
+
+// WithRequestTimeout sets the maximum duration of the generation of a badge,
+// which can require several upstream requests. Zero means no timeout.
+func WithRequestTimeout(timeout time.Duration) ServerOption {
+	return func(server *Server) {
+		server.requestTimeout = timeout
+	}
+}
+
+// WithAdminAddr serves the metrics, the status and the probes on a separate
+// address, instead of the badge address. It applies to ListenAndServe,
+// ListenAndServeTLS, Serve and ServeTLS, see also AdminHandler.
+func WithAdminAddr(addr string) ServerOption {
+	return func(server *Server) {
+		server.adminAddr = addr
+	}
+}
+
+// WithRetryPolicy sets how requests to the upstream repository failing with a
+// temporary error are retried. Defaults to DefaultRetryPolicy. It is ignored
+// by providers set with WithProvider and WithCredentialRoutes, which are
+// configured with their own options, such as WithBBRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) ServerOption {
+	return func(server *Server) {
+		server.retryPolicy = policy
+	}
+}
+
+// WithRateLimiter sets the rate limiter of the requests to the upstream
+// repository. Servers and providers using the same accounts should share it.
+// Defaults to a rate limiter of the server with DefaultRateLimitPolicy. It is
+// ignored by providers set with WithProvider and WithCredentialRoutes, see
+// WithBBRateLimiter.
+func WithRateLimiter(limiter *RateLimiter) ServerOption {
+	return func(server *Server) {
+		server.rateLimiter = limiter
+	}
+}
+
+// WithCircuitBreakerPolicy sets when the circuit breakers of the providers
+// open, and for how long. Defaults to DefaultCircuitBreakerPolicy.
+func WithCircuitBreakerPolicy(policy CircuitBreakerPolicy) ServerOption {
+	return func(server *Server) {
+		server.breakerPolicy = policy
+	}
+}
+
+// WithDraftPolicy sets how draft pull requests are detected and accounted for.
+// It is ignored by providers set with WithProvider and WithCredentialRoutes,
+// see WithBBDraftPolicy.
+func WithDraftPolicy(policy DraftPolicy) ServerOption {
+	return func(server *Server) {
+		server.draftPolicy = policy
+	}
+}
+
+// WithThroughputPolicy sets the window over which merged pull requests are
+// counted. It is ignored by providers set with WithProvider and
+// WithCredentialRoutes, see WithBBThroughputPolicy.
+func WithThroughputPolicy(policy ThroughputPolicy) ServerOption {
+	return func(server *Server) {
+		server.throughputPolicy = policy
+	}
+}
+
+// WithStaleBranchPolicy sets the age after which branches are stale. It is
+// ignored by providers set with WithProvider and WithCredentialRoutes, see
+// WithBBStaleBranchPolicy.
+func WithStaleBranchPolicy(policy StaleBranchPolicy) ServerOption {
+	return func(server *Server) {
+		server.staleBranchPolicy = policy
+	}
+}
+
+// WithContributorsPolicy sets the window over which commit authors are
+// considered. It is ignored by providers set with WithProvider and
+// WithCredentialRoutes, see WithBBContributorsPolicy.
+func WithContributorsPolicy(policy ContributorsPolicy) ServerOption {
+	return func(server *Server) {
+		server.contributorsPolicy = policy
+	}
+}
+
+// WithWorkspacePolicy sets how many repositories are queried concurrently for
+// workspace badges, and how many pull request builds by the default provider.
+func WithWorkspacePolicy(policy WorkspacePolicy) ServerOption {
+	return func(server *Server) {
+		server.workspacePolicy = policy
+	}
+}
+
+// WithHealthPolicy sets the criteria of health scores. Invalid policies are
+// ignored with an error logged, see HealthPolicy.Validate. Defaults to
+// DefaultHealthPolicy.
+func WithHealthPolicy(policy HealthPolicy) ServerOption {
+	return func(server *Server) {
+		err := policy.Validate()
+		if err != nil {
+			log.Error("Ignoring invalid health policy: ", err)
+			return
+		}
+
+		server.healthPolicy = policy
+	}
+}
+
+// WithBusinessHoursPolicy sets whether pull request durations are counted in
+// working hours. Invalid policies are ignored with an error logged, see
+// BusinessHoursPolicy.Validate. Providers set with WithProvider and
+// WithCredentialRoutes count durations according to their own options, see
+// WithBBBusinessHoursPolicy, and should match the policy of the server, which
+// labels durations.
+func WithBusinessHoursPolicy(policy BusinessHoursPolicy) ServerOption {
+	return func(server *Server) {
+		err := policy.Validate()
+		if err != nil {
+			log.Error("Ignoring invalid business hours policy: ", err)
+			return
+		}
+
+		server.businessHours = policy
+	}
+}
+
+// WithThresholdPolicy sets the values at which badges change color. Invalid
+// policies are ignored with an error logged, see ThresholdPolicy.Validate.
+// Defaults to DefaultThresholdPolicy.
+func WithThresholdPolicy(policy ThresholdPolicy) ServerOption {
+	return func(server *Server) {
+		err := policy.Validate()
+		if err != nil {
+			log.Error("Ignoring invalid threshold policy: ", err)
+			return
+		}
+
+		server.factory.thresholds = policy
+	}
+}
+
+// WithDurationFormat sets the duration format used when the request doesn't
+// select one. Unsupported formats are ignored with an error logged. Defaults
+// to LongDurationFormat.
+func WithDurationFormat(name string) ServerOption {
+	return func(server *Server) {
+		if _, found := durationFormatters[name]; !found {
+			log.Error("Ignoring unsupported duration format '", name, "'")
+			return
+		}
+
+		server.durationFormat = name
+	}
+}
+
+// NewServer returns a server configured with options. By default, it has no
+// credentials, uses its own cache with DefaultCachePolicy, queries BitBucket
+// Cloud and renders badges with shields.io. Its policies are the defaults,
+// regardless of the global policies used by the package level functions.
+func NewServer(options ...ServerOption) *Server {
+	server := &Server{
+		drainTimeout:   DefaultDrainTimeout,
+		callTimeout:    DefaultCallTimeout,
+		requestTimeout: DefaultRequestTimeout,
+
+		retryPolicy:        DefaultRetryPolicy,
+		breakerPolicy:      DefaultCircuitBreakerPolicy,
+		draftPolicy:        DraftPolicy{TitlePatterns: DefaultDraftTitlePatterns},
+		throughputPolicy:   ThroughputPolicy{Window: DefaultThroughputWindow},
+		staleBranchPolicy:  StaleBranchPolicy{Age: DefaultStaleBranchAge},
+		contributorsPolicy: ContributorsPolicy{Window: DefaultContributorsWindow},
+		workspacePolicy:    WorkspacePolicy{Workers: DefaultWorkspaceWorkers},
+		healthPolicy:       DefaultHealthPolicy(),
+		businessHours:      defaultBusinessHoursPolicy(),
+		durationFormat:     LongDurationFormat,
+		factory:            badgeFactory{thresholds: DefaultThresholdPolicy()},
+	}
+	for _, option := range options {
+		option(server)
+	}
+	server.factory.workHours = server.businessHours.Enabled
+
+	if server.client == nil {
+		server.client = &http.Client{}
+	}
+	if server.cache == nil {
+		server.cache = NewCache(DefaultCachePolicy)
+	}
+	if server.rateLimiter == nil {
+		server.rateLimiter = NewRateLimiter(DefaultRateLimitPolicy)
+	}
+	// Without credentials, routes are the only providers.
+	if server.provider == nil && (server.config != Config{} || len(server.credentialRoutes) == 0) {
+		bb := NewBBCloud(server.config, server.client,
+			WithBBRetryPolicy(server.retryPolicy),
+			WithBBRateLimiter(server.rateLimiter),
+			WithBBDraftPolicy(server.draftPolicy),
+			WithBBThroughputPolicy(server.throughputPolicy),
+			WithBBStaleBranchPolicy(server.staleBranchPolicy),
+			WithBBContributorsPolicy(server.contributorsPolicy),
+			WithBBWorkspacePolicy(server.workspacePolicy),
+			WithBBBusinessHoursPolicy(server.businessHours))
+		bb.SetCallTimeout(server.callTimeout)
+		server.provider = bb
+	}
+	if server.renderer == nil {
+		server.renderer = ShieldsRenderer{
+			Client:  server.client,
+			Timeout: server.callTimeout,
+		}
+	}
+
+	server.readinessChecks = []*readinessCheck{}
+	for _, route := range server.credentialRoutes {
+		providerRoute := newProviderRoute(route, server.breakerPolicy)
+		server.routes = append(server.routes, providerRoute)
+		server.readinessChecks = append(server.readinessChecks,
+			newReadinessCheck("provider "+route.patterns(), providerRoute.checker()))
+	}
+	if server.provider != nil {
+		providerRoute := newProviderRoute(CredentialRoute{Provider: server.provider}, server.breakerPolicy)
+		server.routes = append(server.routes, providerRoute)
+		server.readinessChecks = append(server.readinessChecks,
+			newReadinessCheck("provider", providerRoute.checker()))
+	}
+	server.provider = server.routes
+
+	server.readinessChecks = append(server.readinessChecks,
+		newReadinessCheck("cache", server.cache),
+		newReadinessCheck("renderer", server.renderer))
+
+	return server
+}
+
+// defaultServer returns a server using the global configuration, cache and
+// policies, used by the package level functions.
+func defaultServer() *Server {
+	return NewServer(
+		WithConfig(config),
+		WithHTTPClient(client),
+		WithCache(defaultCache),
+		WithRetryPolicy(retryPolicy),
+		WithRateLimiter(defaultRateLimiter),
+		WithCircuitBreakerPolicy(circuitBreakerPolicy),
+		WithDraftPolicy(draftPolicy),
+		WithThroughputPolicy(throughputPolicy),
+		WithStaleBranchPolicy(staleBranchPolicy),
+		WithContributorsPolicy(contributorsPolicy),
+		WithWorkspacePolicy(workspacePolicy),
+		WithHealthPolicy(healthPolicy),
+		WithBusinessHoursPolicy(businessHoursPolicy),
+		WithThresholdPolicy(thresholdPolicy),
+		WithDurationFormat(defaultDurationFormat))
+}
+
+// providerName returns the name of a provider, if it has one.
+func providerName(provider Provider) string {
+	if named, ok := provider.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+
+	return "provider"
+}
+
+// ServeHTTP serves the badge requested by r. Unless the server has a
+// separate admin address, it also serves the admin endpoints.
+func (server *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if server.adminAddr == "" && server.serveAdmin(w, r) {
+		return
+	}
+
+	start := time.Now()
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	badgeType := server.serveBadge(recorder, r)
+
+	status := strconv.Itoa(recorder.status)
+	requestsTotal.add(1, badgeType, status)
+	requestDuration.observe(time.Since(start).Seconds(), badgeType, status)
+}
+
+// AdminHandler returns the handler of the admin endpoints: the metrics on
+// MetricsPath, the server status on StatusPath, and the liveness and
+// readiness probes on HealthPath and ReadyPath.
+func (server *Server) AdminHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !server.serveAdmin(w, r) {
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// serveAdmin serves the admin endpoint requested by r, and returns false if r
+// is not an admin request.
+func (server *Server) serveAdmin(w http.ResponseWriter, r *http.Request) bool {
+	switch r.URL.Path {
+	case MetricsPath:
+		server.serveMetrics(w)
+	case StatusPath:
+		server.serveStatus(w)
+	case HealthPath:
+		server.serveHealth(w)
+	case ReadyPath:
+		server.serveReady(w, r)
+	default:
+		return false
+	}
+
+	return true
+}
+
+// serveBadge serves the badge requested by r, and returns its type, or
+// "invalid" if the request is invalid.
+func (server *Server) serveBadge(w http.ResponseWriter, r *http.Request) string {
+	request, httpError := parseHTTPRequest(r)
+	if httpError != nil {
+		http.Error(w, httpError.Message, httpError.HTTPErrorStatus)
+		return "invalid"
+	}
+
+	log.Info("Creating badge for ", request.Username, "/", request.Repository, "/", request.Type)
+
+	// The context is cancelled if the client disconnects.
+	ctx := r.Context()
+	if server.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, server.requestTimeout)
+		defer cancel()
+	}
+
+	badgeImage := server.cache.Get(*request)
+	if badgeImage == nil {
+		newBadgeImage, err := server.GenerateBadge(ctx, *request)
+		switch {
+		case ctx.Err() == context.DeadlineExceeded:
+			log.Warn("Timed out creating badge for ", request.Username, "/", request.Repository, "/", request.Type)
+			http.Error(w, "Timed out while generating the badge", http.StatusGatewayTimeout)
+			return string(request.Type)
+		case err == ErrCircuitOpen:
+			server.serveUnavailable(ctx, w, *request)
+			return string(request.Type)
+		case err == ErrNoCredentials:
+			log.Warn("No credentials for ", request.Username, "/", request.Repository, "/", request.Type)
+			server.serveNoCredentials(ctx, w, *request)
+			return string(request.Type)
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return string(request.Type)
+		}
+
+		badgeImage = newBadgeImage
+		server.cache.Store(*request, badgeImage)
+	}
+
+	sendHTTPReponse(w, badgeImage)
+
+	return string(request.Type)
+}
+
+func parseHTTPRequest(r *http.Request) (*BadgeRequest, *serverError) {
+	paths := strings.Split(r.URL.Path, "/")
+	paths = paths[1:]
+
+	if len(paths) < 3 {
+		log.Warn("Invalid request: ", r.URL)
+		errorMessage := "Requires a request of the form: '<username>/<repository-slug>/<type>[/<branch>]'"
+		return nil, &serverError{
+			Message:         errorMessage,
+			HTTPErrorStatus: http.StatusBadRequest,
+		}
+	}
+
+	// The extension of the badge type selects the output format. Branch names
+	// given in the path can end with ".json", so JSON badges of a branch
+	// select it with the "branch" query parameter.
+	jsonFormat := false
+	if len(paths) == 3 {
+		jsonFormat = strings.HasSuffix(paths[2], ".json")
+		paths[2] = strings.TrimSuffix(paths[2], ".json")
+	}
+	paths[len(paths)-1] = strings.TrimSuffix(paths[len(paths)-1], ".svg")
+
+	badgeType, err := GetBadgeType(paths[2])
+	if err != nil {
+		log.Warn("Invalid request: ", r.URL)
+		return nil, &serverError{
+			Message:         err.Error(),
+			HTTPErrorStatus: http.StatusBadRequest,
+		}
+	}
+
+	query := r.URL.Query()
+	request := &BadgeRequest{
+		Username:   paths[0],
+		Repository: paths[1],
+		Type:       badgeType,
+		Branch:     query.Get("branch"),
+		User:       query.Get("user"),
+		JSON:       jsonFormat,
+		Kind:       query.Get("kind"),
+		Priority:   query.Get("priority"),
+		TagPrefix:  strings.TrimSuffix(query.Get("prefix"), "*"),
+		Show:       query.Get("show"),
+		Label:      query.Get("label"),
+		Project:    query.Get("project"),
+		Include:    query.Get("include"),
+		Exclude:    query.Get("exclude"),
+
+		Target:        query.Get("target"),
+		ExcludeAuthor: query.Get("excludeAuthor"),
+		TitleMatch:    query.Get("titleMatch"),
+
+		Language:       strings.ToLower(query.Get("lang")),
+		DurationFormat: query.Get("durationFormat"),
+	}
+
+	// An explicit language takes precedence over the browser preferences.
+	if request.Language == "" {
+		request.Language = negotiateLanguage(r.Header.Get("Accept-Language"))
+	}
+
+	_, err = NewLocalizer(request.Language, request.DurationFormat)
+	if err != nil {
+		log.Warn("Invalid request: ", r.URL)
+		return nil, &serverError{
+			Message:         err.Error(),
+			HTTPErrorStatus: http.StatusBadRequest,
+		}
+	}
+
+	if request.TitleMatch != "" {
+		_, err := regexp.Compile(request.TitleMatch)
+		if err != nil {
+			log.Warn("Invalid request: ", r.URL)
+			return nil, &serverError{
+				Message:         "Invalid 'titleMatch' query parameter: " + err.Error(),
+				HTTPErrorStatus: http.StatusBadRequest,
+			}
+		}
+	}
+
+	if preRelease := query.Get("prerelease"); preRelease != "" {
+		var err error
+		request.PreRelease, err = strconv.ParseBool(preRelease)
+		if err != nil {
+			log.Warn("Invalid request: ", r.URL)
+			return nil, &serverError{
+				Message:         "The 'prerelease' query parameter must be a boolean",
+				HTTPErrorStatus: http.StatusBadRequest,
+			}
+		}
+	}
+
+	if request.Kind != "" && !stringInSlice(request.Kind, IssueKinds) {
+		log.Warn("Invalid request: ", r.URL)
+		return nil, &serverError{
+			Message:         "The 'kind' query parameter must be one of '" + strings.Join(IssueKinds, "', '") + "'",
+			HTTPErrorStatus: http.StatusBadRequest,
+		}
+	}
+	if request.Priority != "" && !stringInSlice(request.Priority, IssuePriorities) {
+		log.Warn("Invalid request: ", r.URL)
+		return nil, &serverError{
+			Message:         "The 'priority' query parameter must be one of '" + strings.Join(IssuePriorities, "', '") + "'",
+			HTTPErrorStatus: http.StatusBadRequest,
+		}
+	}
+
+	// The branch can also be given as the remainder of the path, as branch
+	// names may contain slashes.
+	if request.Branch == "" && len(paths) > 3 {
+		request.Branch = strings.Join(paths[3:], "/")
+	}
+
+	days, httpError := parsePositiveQueryInt(r, "days")
+	if httpError != nil {
+		return nil, httpError
+	}
+	weeks, httpError := parsePositiveQueryInt(r, "weeks")
+	if httpError != nil {
+		return nil, httpError
+	}
+	request.Days = days + 7*weeks
+
+	requestedTypes := []BadgeType{request.Type}
+	if request.Type == SummaryType {
+		summaryParts, err := parseSummaryParts(request.Show)
+		if err != nil {
+			log.Warn("Invalid request: ", r.URL)
+			return nil, &serverError{
+				Message:         "Invalid 'show' query parameter: " + err.Error(),
+				HTTPErrorStatus: http.StatusBadRequest,
+			}
+		}
+
+		requestedTypes = summaryParts
+	}
+
+	if request.Repository == WorkspaceRepositories {
+		httpError := validateWorkspaceRequest(request, requestedTypes)
+		if httpError != nil {
+			log.Warn("Invalid request: ", r.URL)
+			return nil, httpError
+		}
+	}
+
+	if badgeTypeInSlice(PendingReviewsType, requestedTypes) && request.User == "" {
+		log.Warn("Invalid request: ", r.URL)
+		return nil, &serverError{
+			Message:         "Badge type '" + string(PendingReviewsType) + "' requires a 'user' query parameter",
+			HTTPErrorStatus: http.StatusBadRequest,
+		}
+	}
+
+	return request, nil
+}
+
+// validateWorkspaceRequest checks that a workspace-wide request only targets
+// pull request badges, and that its repository patterns are valid.
+func validateWorkspaceRequest(request *BadgeRequest, requestedTypes []BadgeType) *serverError {
+	for _, requestedType := range requestedTypes {
+		if metricsKind(requestedType) != "pull request" {
+			return &serverError{
+				Message:         "Badge type '" + string(requestedType) + "' is not available for a whole workspace",
+				HTTPErrorStatus: http.StatusBadRequest,
+			}
+		}
+	}
+
+	for _, patterns := range []string{request.Include, request.Exclude} {
+		if patterns == "" {
+			continue
+		}
+
+		err := validateRepositoryPatterns(patterns)
+		if err != nil {
+			return &serverError{
+				Message:         "Invalid repository pattern '" + patterns + "': " + err.Error(),
+				HTTPErrorStatus: http.StatusBadRequest,
+			}
+		}
+	}
+
+	return nil
+}
+
+// parsePositiveQueryInt returns the value of the name query parameter, or 0
+// if it is not set.
+func parsePositiveQueryInt(r *http.Request, name string) (int, *serverError) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number <= 0 {
+		log.Warn("Invalid request: ", r.URL)
+		return 0, &serverError{
+			Message:         "The '" + name + "' query parameter must be a positive number",
+			HTTPErrorStatus: http.StatusBadRequest,
+		}
+	}
+
+	return number, nil
+}
+
+func stringInSlice(value string, slice []string) bool {
+	for _, element := range slice {
+		if element == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func badgeTypeInSlice(badgeType BadgeType, slice []BadgeType) bool {
+	for _, element := range slice {
+		if element == badgeType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sendHTTPReponse(w http.ResponseWriter, badgeImage *BadgeImage) {
+	// Badges are localized from the browser preferences, unless the request
+	// selects a language.
+	w.Header().Set("Vary", "Accept-Language")
+	if badgeImage.Extension == "json" {
+		w.Header().Set("Content-Type", "application/json")
+	} else {
+		w.Header().Set("Content-Type", "image/"+badgeImage.Extension)
+	}
+	fmt.Fprintf(w, "%s", badgeImage.Data)
+}
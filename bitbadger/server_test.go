@@ -0,0 +1,257 @@
+package bitbadger
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseHTTPRequest(t *testing.T) {
+	cases := []struct {
+		url      string
+		expected BadgeRequest
+	}{
+		{"/user/repo/open-pr-count", BadgeRequest{
+			Username: "user", Repository: "repo", Type: OpenPRCountType}},
+		{"/user/repo/open-pr-count.svg", BadgeRequest{
+			Username: "user", Repository: "repo", Type: OpenPRCountType}},
+		{"/user/repo/build-status?branch=develop", BadgeRequest{
+			Username: "user", Repository: "repo", Type: BuildStatusType, Branch: "develop"}},
+		{"/user/repo/last-commit/feature/some-work.svg", BadgeRequest{
+			Username: "user", Repository: "repo", Type: LastCommitType, Branch: "feature/some-work"}},
+		{"/user/repo/pending-reviews?user=alice", BadgeRequest{
+			Username: "user", Repository: "repo", Type: PendingReviewsType, User: "alice"}},
+		{"/user/repo/commit-activity?weeks=2", BadgeRequest{
+			Username: "user", Repository: "repo", Type: CommitActivityType, Days: 14}},
+		{"/user/repo/merged-prs-per-week?days=10", BadgeRequest{
+			Username: "user", Repository: "repo", Type: MergedPRsPerWeekType, Days: 10}},
+		{"/user/repo/open-issue-count?kind=bug&priority=major", BadgeRequest{
+			Username: "user", Repository: "repo", Type: OpenIssueCountType, Kind: "bug", Priority: "major"}},
+		{"/user/repo/latest-tag?prefix=v*&prerelease=true", BadgeRequest{
+			Username: "user", Repository: "repo", Type: LatestTagType, TagPrefix: "v", PreRelease: true}},
+		{"/user/repo/summary?show=open-pr-count,build-status&label=PRs", BadgeRequest{
+			Username: "user", Repository: "repo", Type: SummaryType, Show: "open-pr-count,build-status", Label: "PRs"}},
+		{"/team/*/open-pr-count?project=PRJ&include=api-*,web&exclude=*-legacy", BadgeRequest{
+			Username: "team", Repository: "*", Type: OpenPRCountType, Project: "PRJ", Include: "api-*,web", Exclude: "*-legacy"}},
+		{"/team/*/summary?show=open-pr-count,health", BadgeRequest{
+			Username: "team", Repository: "*", Type: SummaryType, Show: "open-pr-count,health"}},
+		{"/user/repo/open-pr-count?target=main&excludeAuthor=renovate-bot&titleMatch=^feat", BadgeRequest{
+			Username: "user", Repository: "repo", Type: OpenPRCountType,
+			Target: "main", ExcludeAuthor: "renovate-bot", TitleMatch: "^feat"}},
+		{"/user/repo/oldest-open-pr-age?lang=FR&durationFormat=compact", BadgeRequest{
+			Username: "user", Repository: "repo", Type: OldestOpenPRAge, Language: "fr", DurationFormat: "compact"}},
+		{"/user/repo/stale-branches.json", BadgeRequest{
+			Username: "user", Repository: "repo", Type: StaleBranchesType, JSON: true}},
+		{"/user/repo/last-commit.json?branch=develop", BadgeRequest{
+			Username: "user", Repository: "repo", Type: LastCommitType, Branch: "develop", JSON: true}},
+		{"/user/repo/last-commit/release/v1.json", BadgeRequest{
+			Username: "user", Repository: "repo", Type: LastCommitType, Branch: "release/v1.json"}},
+	}
+
+	for _, c := range cases {
+		request, httpError := parseHTTPRequest(httptest.NewRequest("GET", c.url, nil))
+		if httpError != nil {
+			t.Errorf("parseHTTPRequest: Unexpected error for '%s': %s", c.url, httpError.Message)
+			continue
+		}
+		if *request != c.expected {
+			t.Errorf("parseHTTPRequest: Expected %+v for '%s', got %+v", c.expected, c.url, *request)
+		}
+	}
+
+	invalidURLs := []string{
+		"/user/repo",
+		"/user/repo/unknown",
+		"/user/repo/pending-reviews",
+		"/user/repo/commit-activity?weeks=-1",
+		"/user/repo/merged-prs-per-week?days=many",
+		"/user/repo/open-issue-count?kind=bug%22%20OR%20kind%3D%22task",
+		"/user/repo/open-issue-count?priority=urgent",
+		"/user/repo/latest-tag?prerelease=maybe",
+		"/user/repo/summary?show=open-pr-count,unknown",
+		"/user/repo/summary?show=summary",
+		"/user/repo/summary?show=pending-reviews",
+		"/team/*/build-status",
+		"/team/*/summary?show=open-pr-count,latest-tag",
+		"/team/*/open-pr-count?include=[api",
+		"/user/repo/open-pr-count?titleMatch=(",
+		"/user/repo/open-pr-count?lang=xx",
+		"/user/repo/open-pr-count?durationFormat=fancy",
+	}
+
+	for _, url := range invalidURLs {
+		_, httpError := parseHTTPRequest(httptest.NewRequest("GET", url, nil))
+		if httpError == nil {
+			t.Errorf("parseHTTPRequest: Should generate an error for '%s'", url)
+		}
+	}
+}
+
+func TestParseHTTPRequestAcceptLanguage(t *testing.T) {
+	cases := []struct {
+		url            string
+		acceptLanguage string
+		expected       string
+	}{
+		{"/user/repo/open-pr-count", "de-CH, de;q=0.9, en;q=0.8", "de"},
+		{"/user/repo/open-pr-count", "ja, es;q=0.5", "es"},
+		{"/user/repo/open-pr-count", "ja", ""},
+		{"/user/repo/open-pr-count?lang=fr", "de", "fr"},
+	}
+
+	for _, c := range cases {
+		httpRequest := httptest.NewRequest("GET", c.url, nil)
+		httpRequest.Header.Set("Accept-Language", c.acceptLanguage)
+
+		request, httpError := parseHTTPRequest(httpRequest)
+		if httpError != nil {
+			t.Errorf("parseHTTPRequest: Unexpected error for '%s': %s", c.acceptLanguage, httpError.Message)
+			continue
+		}
+		if request.Language != c.expected {
+			t.Errorf("parseHTTPRequest: Expected language '%s' for '%s', got '%s'", c.expected, c.acceptLanguage, request.Language)
+		}
+	}
+}
+
+// fakeProvider returns fixed pull request information, and counts requests.
+type fakeProvider struct {
+	openCount int
+	requests  int
+	// err is returned instead of pull request information if set.
+	err error
+}
+
+func (provider *fakeProvider) RetrievePullRequestInfo(ctx context.Context, request BadgeRequest) (PullRequestsInfo, error) {
+	provider.requests++
+	if provider.err != nil {
+		return PullRequestsInfo{}, provider.err
+	}
+	return PullRequestsInfo{OpenCount: provider.openCount}, nil
+}
+
+func (provider *fakeProvider) RetrieveBuildInfo(ctx context.Context, request BadgeRequest) (BuildInfo, error) {
+	return BuildInfo{}, errors.New("Not supported")
+}
+
+func (provider *fakeProvider) RetrieveCommitsInfo(ctx context.Context, request BadgeRequest) (CommitsInfo, error) {
+	return CommitsInfo{}, errors.New("Not supported")
+}
+
+func (provider *fakeProvider) RetrieveBranchesInfo(ctx context.Context, request BadgeRequest) (BranchesInfo, error) {
+	return BranchesInfo{}, errors.New("Not supported")
+}
+
+func (provider *fakeProvider) RetrieveIssuesInfo(ctx context.Context, request BadgeRequest) (IssuesInfo, error) {
+	return IssuesInfo{}, errors.New("Not supported")
+}
+
+func (provider *fakeProvider) RetrieveTagsInfo(ctx context.Context, request BadgeRequest) (TagsInfo, error) {
+	return TagsInfo{}, errors.New("Not supported")
+}
+
+func (provider *fakeProvider) RetrieveRepositories(ctx context.Context, request BadgeRequest) ([]string, error) {
+	return []string{"repo1", "repo2"}, nil
+}
+
+func TestServerServeHTTP(t *testing.T) {
+	provider1 := &fakeProvider{openCount: 3}
+	provider2 := &fakeProvider{openCount: 12}
+	server1 := NewServer(WithProvider(provider1), WithRenderer(LocalRenderer{}))
+	server2 := NewServer(WithProvider(provider2), WithRenderer(LocalRenderer{}))
+
+	cases := []struct {
+		server          *Server
+		url             string
+		expectedStatus  int
+		expectedContent string
+	}{
+		{server1, "/user/repo/open-pr-count", http.StatusOK, ">3</text>"},
+		{server2, "/user/repo/open-pr-count", http.StatusOK, ">12</text>"},
+		{server1, "/user/repo/open-pr-count.json", http.StatusOK, `"Message":"3"`},
+		{server2, "/user/*/open-pr-count.json", http.StatusOK, `"Message":"24"`},
+		{server1, "/user/repo/build-status", http.StatusBadGateway, "build info"},
+		{server1, "/user/repo/unknown", http.StatusBadRequest, "unknown"},
+	}
+
+	for _, c := range cases {
+		recorder := httptest.NewRecorder()
+		c.server.ServeHTTP(recorder, httptest.NewRequest("GET", c.url, nil))
+
+		if recorder.Code != c.expectedStatus {
+			t.Errorf("ServeHTTP: Expected status %d for '%s', got %d", c.expectedStatus, c.url, recorder.Code)
+		}
+		if !strings.Contains(recorder.Body.String(), c.expectedContent) {
+			t.Errorf("ServeHTTP: Expected '%s' in the response to '%s', got '%s'", c.expectedContent, c.url, recorder.Body.String())
+		}
+		if recorder.Code == http.StatusOK && recorder.Header().Get("Vary") != "Accept-Language" {
+			t.Errorf("ServeHTTP: Expected the response to '%s' to vary with Accept-Language", c.url)
+		}
+	}
+
+	// Servers have their own cache by default.
+	requests := provider1.requests
+	server1.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/user/repo/open-pr-count", nil))
+	if provider1.requests != requests {
+		t.Errorf("ServeHTTP: The result should be cached")
+	}
+	if !server1.cache.Cached(BadgeRequest{Username: "user", Repository: "repo", Type: OpenPRCountType}) {
+		t.Errorf("ServeHTTP: The result should be in the server cache")
+	}
+	if RequestCached(BadgeRequest{Username: "user", Repository: "repo", Type: OpenPRCountType}) {
+		t.Errorf("ServeHTTP: The result should not be in the global cache")
+	}
+}
+
+func TestServerPolicies(t *testing.T) {
+	// Global policies only apply to the package level functions.
+	defer SetThresholdPolicy(GetThresholdPolicy())
+	global := DefaultThresholdPolicy()
+	global.OpenPRCount = []float64{100, 200, 300, 400}
+	SetThresholdPolicy(global)
+
+	lenient := DefaultThresholdPolicy()
+	lenient.OpenPRCount = []float64{20, 30, 40, 50}
+	invalid := DefaultThresholdPolicy()
+	invalid.OpenPRCount = []float64{1}
+
+	provider := &fakeProvider{openCount: 12}
+	cases := []struct {
+		server        *Server
+		expectedColor string
+	}{
+		{NewServer(WithProvider(provider)), `"Color":"red"`},
+		{NewServer(WithProvider(provider), WithThresholdPolicy(lenient)), `"Color":"green"`},
+		{NewServer(WithProvider(provider), WithThresholdPolicy(invalid)), `"Color":"red"`},
+	}
+
+	for _, c := range cases {
+		recorder := httptest.NewRecorder()
+		c.server.ServeHTTP(recorder, httptest.NewRequest("GET", "/user/repo/open-pr-count.json", nil))
+
+		if !strings.Contains(recorder.Body.String(), c.expectedColor) {
+			t.Errorf("ServeHTTP: Expected '%s' in the response, got '%s'", c.expectedColor, recorder.Body.String())
+		}
+	}
+}
+
+func TestServerRequestTimeout(t *testing.T) {
+	server := NewServer(
+		WithProvider(&slowProvider{fakeProvider{openCount: 3}, time.Minute}),
+		WithRenderer(LocalRenderer{}),
+		WithRequestTimeout(50*time.Millisecond))
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest("GET", "/user/repo/open-pr-count", nil))
+
+	if recorder.Code != http.StatusGatewayTimeout {
+		t.Errorf("ServeHTTP: Expected status %d, got %d", http.StatusGatewayTimeout, recorder.Code)
+	}
+	if server.cache.Cached(BadgeRequest{Username: "user", Repository: "repo", Type: OpenPRCountType}) {
+		t.Errorf("ServeHTTP: Timed out requests should not be cached")
+	}
+}
@@ -0,0 +1,644 @@
+package bitbadger
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	log "github.com/Sirupsen/logrus"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ServerConfig holds the settings of a BitBadger server. It is loaded from a
+// YAML file with LoadServerConfig, and can be overridden with environment
+// variables, see ApplyEnvironment.
+type ServerConfig struct {
+	Debug       bool              `yaml:"debug"`
+	Credentials CredentialsConfig `yaml:"credentials"`
+	Provider    ProviderConfig    `yaml:"provider"`
+	Listen      ListenConfig      `yaml:"listen"`
+	Cache       CacheConfig       `yaml:"cache"`
+	Policies    PoliciesConfig    `yaml:"policies"`
+	// CredentialRoutes select other credentials for some workspaces, see
+	// WithCredentialRoutes.
+	CredentialRoutes []CredentialRouteConfig `yaml:"credentialRoutes"`
+}
+
+// CredentialsConfig holds the credentials used to authenticate to the
+// upstream repository. Secrets can be read from files instead, such as
+// mounted secrets.
+type CredentialsConfig struct {
+	// Auth is the authentication method, see AuthMethod.
+	Auth             string `yaml:"auth"`
+	Username         string `yaml:"username"`
+	Password         string `yaml:"password"`
+	PasswordFile     string `yaml:"passwordFile"`
+	ClientID         string `yaml:"clientId"`
+	ClientSecret     string `yaml:"clientSecret"`
+	ClientSecretFile string `yaml:"clientSecretFile"`
+	AccessToken      string `yaml:"accessToken"`
+	AccessTokenFile  string `yaml:"accessTokenFile"`
+}
+
+// Empty returns true if no credentials are set, in which case only the
+// credential routes are used.
+func (credentials CredentialsConfig) Empty() bool {
+	credentials.Auth = ""
+	return credentials == CredentialsConfig{}
+}
+
+// CredentialRouteConfig holds the credentials used for the workspaces, and
+// optionally the projects, matching its patterns.
+type CredentialRouteConfig struct {
+	Workspace string `yaml:"workspace"`
+	Project   string `yaml:"project"`
+	// Provider is the kind of upstream repository, provider.type by default.
+	Provider    string            `yaml:"provider"`
+	Credentials CredentialsConfig `yaml:"credentials"`
+}
+
+// ProviderConfig holds the settings of the upstream repository.
+type ProviderConfig struct {
+	// Type is the kind of upstream repository. Only "bbcloud" is supported.
+	Type             string   `yaml:"type"`
+	CallTimeout      Duration `yaml:"callTimeout"`
+	Retries          int      `yaml:"retries"`
+	RateLimit        int      `yaml:"rateLimit"`
+	RateBurst        int      `yaml:"rateBurst"`
+	BreakerThreshold int      `yaml:"breakerThreshold"`
+	BreakerDuration  Duration `yaml:"breakerDuration"`
+}
+
+// ListenConfig holds the listeners of the server.
+type ListenConfig struct {
+	Port           int       `yaml:"port"`
+	AdminPort      int       `yaml:"adminPort"`
+	Insecure       bool      `yaml:"insecure"`
+	TLS            TLSConfig `yaml:"tls"`
+	DrainTimeout   Duration  `yaml:"drainTimeout"`
+	RequestTimeout Duration  `yaml:"requestTimeout"`
+}
+
+// TLSConfig holds the paths of the TLS certificate and private key.
+type TLSConfig struct {
+	Cert string `yaml:"cert"`
+	Key  string `yaml:"key"`
+}
+
+// CacheConfig holds the cache policy.
+type CacheConfig struct {
+	Validity     Duration `yaml:"validity"`
+	LongValidity Duration `yaml:"longValidity"`
+	MaxCached    int      `yaml:"maxCached"`
+}
+
+// PoliciesConfig holds the policies used to compute badges.
+type PoliciesConfig struct {
+	DraftPatterns          []string            `yaml:"draftPatterns"`
+	ExcludeDrafts          bool                `yaml:"excludeDrafts"`
+	ThroughputWindowDays   int                 `yaml:"throughputWindowDays"`
+	StaleBranchAgeDays     int                 `yaml:"staleBranchAgeDays"`
+	ContributorsWindowDays int                 `yaml:"contributorsWindowDays"`
+	Workers                int                 `yaml:"workers"`
+	HealthConfig           string              `yaml:"healthConfig"`
+	DurationFormat         string              `yaml:"durationFormat"`
+	BusinessHours          BusinessHoursConfig `yaml:"businessHours"`
+	Thresholds             ThresholdPolicy     `yaml:"thresholds"`
+}
+
+// BusinessHoursConfig holds the business hours policy, see
+// BusinessHoursPolicy.
+type BusinessHoursConfig struct {
+	Enabled      bool   `yaml:"enabled"`
+	Timezone     string `yaml:"timezone"`
+	WorkDays     string `yaml:"workDays"`
+	WorkHours    string `yaml:"workHours"`
+	HolidaysFile string `yaml:"holidaysFile"`
+}
+
+// Duration is a time.Duration written as a string such as "90s" or "12h" in
+// configuration files.
+type Duration time.Duration
+
+// UnmarshalYAML parses a duration string.
+func (duration *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value string
+	err := unmarshal(&value)
+	if err != nil {
+		return err
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+
+	*duration = Duration(parsed)
+	return nil
+}
+
+// DefaultServerConfig returns the settings used unless configured otherwise.
+func DefaultServerConfig() ServerConfig {
+	return ServerConfig{
+		Credentials: CredentialsConfig{
+			Auth: string(AppPasswordAuth),
+		},
+		Provider: ProviderConfig{
+			Type:             "bbcloud",
+			CallTimeout:      Duration(DefaultCallTimeout),
+			Retries:          DefaultRetryPolicy.MaxRetries,
+			RateLimit:        DefaultRateLimitPolicy.RequestsPerHour,
+			RateBurst:        DefaultRateLimitPolicy.Burst,
+			BreakerThreshold: DefaultCircuitBreakerPolicy.FailureThreshold,
+			BreakerDuration:  Duration(DefaultCircuitBreakerPolicy.OpenDuration),
+		},
+		Listen: ListenConfig{
+			Port:           34000,
+			DrainTimeout:   Duration(DefaultDrainTimeout),
+			RequestTimeout: Duration(DefaultRequestTimeout),
+		},
+		Cache: CacheConfig{
+			Validity:     0,
+			LongValidity: Duration(12 * time.Hour),
+			MaxCached:    100,
+		},
+		Policies: PoliciesConfig{
+			DraftPatterns:          DefaultDraftTitlePatterns,
+			ThroughputWindowDays:   int(DefaultThroughputWindow / (24 * time.Hour)),
+			StaleBranchAgeDays:     int(DefaultStaleBranchAge / (24 * time.Hour)),
+			ContributorsWindowDays: int(DefaultContributorsWindow / (24 * time.Hour)),
+			Workers:                DefaultWorkspaceWorkers,
+			DurationFormat:         LongDurationFormat,
+			BusinessHours: BusinessHoursConfig{
+				Timezone:  "UTC",
+				WorkDays:  "mon,tue,wed,thu,fri",
+				WorkHours: "09:00-17:00",
+			},
+			Thresholds: DefaultThresholdPolicy(),
+		},
+	}
+}
+
+// LoadServerConfig loads settings from a YAML file. Settings which are not in
+// the file keep their default values.
+func LoadServerConfig(path string) (ServerConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ServerConfig{}, err
+	}
+
+	return parseServerConfig(data)
+}
+
+func parseServerConfig(data []byte) (ServerConfig, error) {
+	config := DefaultServerConfig()
+	err := yaml.UnmarshalStrict(data, &config)
+	if err != nil {
+		return ServerConfig{}, err
+	}
+
+	return config, nil
+}
+
+// EnvironmentPrefix is the prefix of the environment variables overriding
+// settings.
+const EnvironmentPrefix = "BITBADGER_"
+
+// ApplyEnvironment overrides settings with environment variables, given as
+// "KEY=value" strings such as returned by os.Environ. Variables are named
+// after the path of the setting in the YAML file, such as
+// BITBADGER_CREDENTIALS_PASSWORD_FILE for credentials.passwordFile. Lists are
+// comma separated. Unknown BITBADGER_ variables are ignored.
+func (config *ServerConfig) ApplyEnvironment(environment []string) error {
+	settings := map[string]reflect.Value{}
+	collectSettings(reflect.ValueOf(config).Elem(), strings.TrimSuffix(EnvironmentPrefix, "_"), settings)
+
+	for _, variable := range environment {
+		parts := strings.SplitN(variable, "=", 2)
+		if len(parts) != 2 || !strings.HasPrefix(parts[0], EnvironmentPrefix) {
+			continue
+		}
+
+		// Unknown variables are not errors, as other tools set variables with
+		// the same prefix, such as the BITBADGER_SERVICE_HOST and
+		// BITBADGER_PORT service links of Kubernetes.
+		setting, found := settings[parts[0]]
+		if !found {
+			log.Warn("Ignoring unknown environment variable ", parts[0])
+			continue
+		}
+
+		err := setSetting(setting, parts[1])
+		if err != nil {
+			return fmt.Errorf("Invalid environment variable %s: %s", parts[0], err)
+		}
+	}
+
+	return nil
+}
+
+var durationType = reflect.TypeOf(Duration(0))
+
+// collectSettings maps the environment variable names of the settings of a
+// struct to their values.
+func collectSettings(value reflect.Value, prefix string, settings map[string]reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		key := strings.Split(value.Type().Field(i).Tag.Get("yaml"), ",")[0]
+		name := prefix + "_" + environmentName(key)
+
+		field := value.Field(i)
+		if field.Kind() == reflect.Struct {
+			collectSettings(field, name, settings)
+			continue
+		}
+		if field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.String {
+			// Lists of settings, such as credential routes, are only set in files.
+			continue
+		}
+
+		settings[name] = field
+	}
+}
+
+// environmentName converts a camel case YAML key to an environment variable
+// name, such as "passwordFile" to "PASSWORD_FILE".
+func environmentName(key string) string {
+	var name strings.Builder
+	for i, r := range key {
+		if i > 0 && unicode.IsUpper(r) {
+			name.WriteRune('_')
+		}
+		name.WriteRune(unicode.ToUpper(r))
+	}
+
+	return name.String()
+}
+
+// setSetting parses value into a setting.
+func setSetting(setting reflect.Value, value string) error {
+	if setting.Type() == durationType {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		setting.SetInt(int64(duration))
+		return nil
+	}
+
+	switch setting.Kind() {
+	case reflect.String:
+		setting.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		setting.SetBool(parsed)
+	case reflect.Int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		setting.SetInt(int64(parsed))
+	case reflect.Slice:
+		values := []string{}
+		for _, element := range strings.Split(value, ",") {
+			if element = strings.TrimSpace(element); element != "" {
+				values = append(values, element)
+			}
+		}
+		setting.Set(reflect.ValueOf(values))
+	default:
+		return errors.New("Unsupported setting type")
+	}
+
+	return nil
+}
+
+// Validate returns an error listing all the invalid settings, or nil if the
+// settings are valid.
+func (config ServerConfig) Validate() error {
+	problems := []string{}
+	check := func(valid bool, problem string) {
+		if !valid {
+			problems = append(problems, problem)
+		}
+	}
+
+	// Without routes, credentials are required. With routes, they are the
+	// credentials of the workspaces matching no route.
+	if len(config.CredentialRoutes) == 0 || !config.Credentials.Empty() {
+		config.Credentials.validate("credentials", check)
+	}
+	for i, route := range config.CredentialRoutes {
+		name := "credentialRoutes[" + strconv.Itoa(i) + "]"
+		check(route.Workspace != "" || route.Project != "", name+".workspace or "+name+".project is required")
+		err := ValidateCredentialRoute(CredentialRoute{Workspace: route.Workspace, Project: route.Project})
+		check(err == nil, name+" has an invalid pattern")
+		check(route.Provider == "" || route.Provider == "bbcloud", name+".provider must be 'bbcloud'")
+		route.Credentials.validate(name+".credentials", check)
+	}
+
+	check(config.Provider.Type == "bbcloud", "provider.type must be 'bbcloud'")
+	check(config.Provider.CallTimeout >= 0, "provider.callTimeout must not be negative")
+	check(config.Provider.Retries >= 0, "provider.retries must not be negative")
+	check(config.Provider.RateLimit >= 0, "provider.rateLimit must not be negative")
+	check(config.Provider.RateBurst >= 0, "provider.rateBurst must not be negative")
+	check(config.Provider.BreakerThreshold >= 0, "provider.breakerThreshold must not be negative")
+	check(config.Provider.BreakerDuration >= 0, "provider.breakerDuration must not be negative")
+
+	listen := config.Listen
+	check(listen.Port > 0 && listen.Port < 65536, "listen.port must be between 1 and 65535")
+	check(listen.AdminPort >= 0 && listen.AdminPort < 65536, "listen.adminPort must be between 0 and 65535")
+	check(listen.AdminPort == 0 || listen.AdminPort != listen.Port, "listen.adminPort must differ from listen.port")
+	check(listen.Insecure || listen.TLS.Cert != "", "listen.tls.cert is required, unless listen.insecure is set")
+	check(listen.Insecure || listen.TLS.Key != "", "listen.tls.key is required, unless listen.insecure is set")
+	check(listen.DrainTimeout >= 0, "listen.drainTimeout must not be negative")
+	check(listen.RequestTimeout >= 0, "listen.requestTimeout must not be negative")
+
+	check(config.Cache.Validity >= 0, "cache.validity must not be negative")
+	check(config.Cache.LongValidity >= 0, "cache.longValidity must not be negative")
+	check(config.Cache.MaxCached >= 0, "cache.maxCached must not be negative")
+
+	policies := config.Policies
+	check(policies.ThroughputWindowDays > 0, "policies.throughputWindowDays must be positive")
+	check(policies.StaleBranchAgeDays > 0, "policies.staleBranchAgeDays must be positive")
+	check(policies.ContributorsWindowDays > 0, "policies.contributorsWindowDays must be positive")
+	check(policies.Workers > 0, "policies.workers must be positive")
+	_, found := durationFormatters[policies.DurationFormat]
+	check(found, "policies.durationFormat '"+policies.DurationFormat+"' is not supported")
+
+	businessHours := policies.BusinessHours
+	_, err := time.LoadLocation(businessHours.Timezone)
+	check(err == nil, "policies.businessHours.timezone '"+businessHours.Timezone+"' is not a valid timezone")
+	_, err = ParseWorkDays(businessHours.WorkDays)
+	check(err == nil, "policies.businessHours.workDays '"+businessHours.WorkDays+"' is not a valid list of days")
+	_, _, err = ParseWorkHours(businessHours.WorkHours)
+	check(err == nil, "policies.businessHours.workHours '"+businessHours.WorkHours+"' is not formatted as HH:MM-HH:MM")
+	err = policies.Thresholds.Validate()
+	check(err == nil, "policies.thresholds: "+fmt.Sprint(err))
+
+	if len(problems) > 0 {
+		return errors.New("Invalid configuration: " + strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// validate checks the credentials, named name in the problems passed to
+// check.
+func (credentials CredentialsConfig) validate(name string, check func(valid bool, problem string)) {
+	checkSecret := func(value string, file string, key string) {
+		check(value == "" || file == "", name+"."+key+" and "+name+"."+key+"File are exclusive")
+		check(value != "" || file != "", name+"."+key+" or "+name+"."+key+"File is required")
+	}
+
+	switch AuthMethod(credentials.Auth) {
+	case AppPasswordAuth, "":
+		check(credentials.Username != "", name+".username is required")
+		checkSecret(credentials.Password, credentials.PasswordFile, "password")
+	case OAuthAuth:
+		check(credentials.ClientID != "", name+".clientId is required")
+		checkSecret(credentials.ClientSecret, credentials.ClientSecretFile, "clientSecret")
+	case AccessTokenAuth:
+		checkSecret(credentials.AccessToken, credentials.AccessTokenFile, "accessToken")
+	default:
+		check(false, name+".auth must be 'app-password', 'oauth' or 'access-token'")
+	}
+}
+
+// LoadSecret reads a secret from a file, such as a password, ignoring the
+// surrounding whitespace.
+func LoadSecret(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+// Apply returns the options of a server using the settings. Its providers,
+// including those of the credential routes, share an HTTP client and a rate
+// limiter. The settings must be valid, see Validate.
+func (config ServerConfig) Apply() ([]ServerOption, error) {
+	credentials, err := config.Credentials.config("credentials")
+	if err != nil {
+		return nil, err
+	}
+
+	policyOptions, bbOptions, err := config.Policies.options()
+	if err != nil {
+		return nil, err
+	}
+
+	provider := config.Provider
+	client := &http.Client{}
+	retryPolicy := DefaultRetryPolicy
+	retryPolicy.MaxRetries = provider.Retries
+	rateLimiter := NewRateLimiter(RateLimitPolicy{
+		RequestsPerHour: provider.RateLimit,
+		Burst:           provider.RateBurst,
+	})
+	bbOptions = append(bbOptions, WithBBRetryPolicy(retryPolicy), WithBBRateLimiter(rateLimiter))
+
+	routes := []CredentialRoute{}
+	for i, route := range config.CredentialRoutes {
+		routeCredentials, err := route.Credentials.config("credentialRoutes[" + strconv.Itoa(i) + "].credentials")
+		if err != nil {
+			return nil, err
+		}
+
+		bb := NewBBCloud(routeCredentials, client, bbOptions...)
+		bb.SetCallTimeout(time.Duration(provider.CallTimeout))
+		routes = append(routes, CredentialRoute{
+			Workspace: route.Workspace,
+			Project:   route.Project,
+			Provider:  bb,
+		})
+	}
+
+	options := []ServerOption{
+		WithHTTPClient(client),
+		WithRetryPolicy(retryPolicy),
+		WithRateLimiter(rateLimiter),
+		WithCircuitBreakerPolicy(CircuitBreakerPolicy{
+			FailureThreshold: provider.BreakerThreshold,
+			OpenDuration:     time.Duration(provider.BreakerDuration),
+		}),
+		WithCredentialRoutes(routes...),
+		WithCache(NewCache(CachePolicy{
+			ValidityDuration:         time.Duration(config.Cache.Validity),
+			MaxCachedResults:         config.Cache.MaxCached,
+			LongTermValidityDuration: time.Duration(config.Cache.LongValidity),
+		})),
+		WithCallTimeout(time.Duration(provider.CallTimeout)),
+		WithDrainTimeout(time.Duration(config.Listen.DrainTimeout)),
+		WithRequestTimeout(time.Duration(config.Listen.RequestTimeout)),
+	}
+	options = append(options, policyOptions...)
+	if !config.Credentials.Empty() {
+		options = append(options, WithConfig(credentials))
+	}
+	if config.Listen.AdminPort != 0 {
+		options = append(options, WithAdminAddr(":"+strconv.Itoa(config.Listen.AdminPort)))
+	}
+
+	return options, nil
+}
+
+// config returns the credentials, read from their files if set. Errors refer
+// to the credentials as name.
+func (credentials CredentialsConfig) config(name string) (Config, error) {
+	secrets := []struct {
+		value *string
+		file  string
+		name  string
+	}{
+		{&credentials.Password, credentials.PasswordFile, name + ".passwordFile"},
+		{&credentials.ClientSecret, credentials.ClientSecretFile, name + ".clientSecretFile"},
+		{&credentials.AccessToken, credentials.AccessTokenFile, name + ".accessTokenFile"},
+	}
+	for _, secret := range secrets {
+		if secret.file == "" {
+			continue
+		}
+
+		value, err := LoadSecret(secret.file)
+		if err != nil {
+			return Config{}, errors.New("Invalid " + secret.name + ": " + err.Error())
+		}
+		*secret.value = value
+	}
+
+	return Config{
+		Auth:         AuthMethod(credentials.Auth),
+		Username:     credentials.Username,
+		Password:     credentials.Password,
+		ClientID:     credentials.ClientID,
+		ClientSecret: credentials.ClientSecret,
+		AccessToken:  credentials.AccessToken,
+	}, nil
+}
+
+// options returns the options of a server using the badge policies, and of
+// the providers of its credential routes.
+func (policies PoliciesConfig) options() ([]ServerOption, []BBCloudOption, error) {
+	draftPolicy := DraftPolicy{
+		TitlePatterns: policies.DraftPatterns,
+		ExcludeDrafts: policies.ExcludeDrafts,
+	}
+	err := draftPolicy.Validate()
+	if err != nil {
+		return nil, nil, errors.New("Invalid draft pattern: " + err.Error())
+	}
+
+	healthPolicy := DefaultHealthPolicy()
+	if policies.HealthConfig != "" {
+		healthPolicy, err = LoadHealthPolicy(policies.HealthConfig)
+		if err != nil {
+			return nil, nil, errors.New("Invalid health configuration: " + err.Error())
+		}
+
+		err = healthPolicy.Validate()
+		if err != nil {
+			return nil, nil, errors.New("Invalid health configuration: " + err.Error())
+		}
+	}
+
+	if _, found := durationFormatters[policies.DurationFormat]; !found {
+		return nil, nil, fmt.Errorf("Unsupported duration format '%s'", policies.DurationFormat)
+	}
+
+	err = policies.Thresholds.Validate()
+	if err != nil {
+		return nil, nil, errors.New("Invalid thresholds: " + err.Error())
+	}
+
+	businessHoursPolicy, err := policies.BusinessHours.policy()
+	if err != nil {
+		return nil, nil, errors.New("Invalid business hours: " + err.Error())
+	}
+
+	throughputPolicy := ThroughputPolicy{
+		Window: time.Duration(policies.ThroughputWindowDays) * 24 * time.Hour,
+	}
+	staleBranchPolicy := StaleBranchPolicy{
+		Age: time.Duration(policies.StaleBranchAgeDays) * 24 * time.Hour,
+	}
+	contributorsPolicy := ContributorsPolicy{
+		Window: time.Duration(policies.ContributorsWindowDays) * 24 * time.Hour,
+	}
+	workspacePolicy := WorkspacePolicy{
+		Workers: policies.Workers,
+	}
+
+	serverOptions := []ServerOption{
+		WithDraftPolicy(draftPolicy),
+		WithThroughputPolicy(throughputPolicy),
+		WithStaleBranchPolicy(staleBranchPolicy),
+		WithContributorsPolicy(contributorsPolicy),
+		WithWorkspacePolicy(workspacePolicy),
+		WithHealthPolicy(healthPolicy),
+		WithBusinessHoursPolicy(businessHoursPolicy),
+		WithThresholdPolicy(policies.Thresholds),
+		WithDurationFormat(policies.DurationFormat),
+	}
+	bbOptions := []BBCloudOption{
+		WithBBDraftPolicy(draftPolicy),
+		WithBBThroughputPolicy(throughputPolicy),
+		WithBBStaleBranchPolicy(staleBranchPolicy),
+		WithBBContributorsPolicy(contributorsPolicy),
+		WithBBWorkspacePolicy(workspacePolicy),
+		WithBBBusinessHoursPolicy(businessHoursPolicy),
+	}
+
+	return serverOptions, bbOptions, nil
+}
+
+// policy returns the business hours policy.
+func (businessHours BusinessHoursConfig) policy() (BusinessHoursPolicy, error) {
+	location, err := time.LoadLocation(businessHours.Timezone)
+	if err != nil {
+		return BusinessHoursPolicy{}, err
+	}
+
+	workDays, err := ParseWorkDays(businessHours.WorkDays)
+	if err != nil {
+		return BusinessHoursPolicy{}, err
+	}
+
+	dayStart, dayEnd, err := ParseWorkHours(businessHours.WorkHours)
+	if err != nil {
+		return BusinessHoursPolicy{}, err
+	}
+
+	holidays := []string{}
+	if businessHours.HolidaysFile != "" {
+		holidays, err = LoadHolidays(businessHours.HolidaysFile)
+		if err != nil {
+			return BusinessHoursPolicy{}, err
+		}
+	}
+
+	policy := BusinessHoursPolicy{
+		Enabled:  businessHours.Enabled,
+		Location: location,
+		WorkDays: workDays,
+		DayStart: dayStart,
+		DayEnd:   dayEnd,
+		Holidays: holidays,
+	}
+	err = policy.Validate()
+	if err != nil {
+		return BusinessHoursPolicy{}, err
+	}
+
+	return policy, nil
+}
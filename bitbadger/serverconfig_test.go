@@ -0,0 +1,346 @@
+package bitbadger
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func validServerConfig() ServerConfig {
+	config := DefaultServerConfig()
+	config.Credentials.Username = "Robert"
+	config.Credentials.Password = "azerty"
+	config.Listen.Insecure = true
+	return config
+}
+
+func TestParseServerConfig(t *testing.T) {
+	config, err := parseServerConfig([]byte(`
+credentials:
+  username: Robert
+  passwordFile: /run/secrets/bitbucket
+provider:
+  callTimeout: 5s
+  retries: 1
+listen:
+  port: 8080
+  tls:
+    cert: cert.pem
+    key: key.pem
+policies:
+  draftPatterns: ["^WIP"]
+  businessHours:
+    enabled: true
+    timezone: Europe/Paris
+  thresholds:
+    openPRCount: [5, 10, 15, 20]
+`))
+	if err != nil {
+		t.Fatalf("parseServerConfig: Unexpected error: %s", err)
+	}
+
+	if config.Credentials.Username != "Robert" || config.Credentials.PasswordFile != "/run/secrets/bitbucket" {
+		t.Errorf("parseServerConfig: Unexpected credentials %+v", config.Credentials)
+	}
+	if time.Duration(config.Provider.CallTimeout) != 5*time.Second || config.Provider.Retries != 1 {
+		t.Errorf("parseServerConfig: Unexpected provider %+v", config.Provider)
+	}
+	if config.Listen.Port != 8080 || config.Listen.TLS.Cert != "cert.pem" || config.Listen.TLS.Key != "key.pem" {
+		t.Errorf("parseServerConfig: Unexpected listeners %+v", config.Listen)
+	}
+	if len(config.Policies.DraftPatterns) != 1 || config.Policies.DraftPatterns[0] != "^WIP" {
+		t.Errorf("parseServerConfig: Unexpected draft patterns %v", config.Policies.DraftPatterns)
+	}
+	if !config.Policies.BusinessHours.Enabled || config.Policies.BusinessHours.Timezone != "Europe/Paris" {
+		t.Errorf("parseServerConfig: Unexpected business hours %+v", config.Policies.BusinessHours)
+	}
+	if !reflect.DeepEqual(config.Policies.Thresholds.OpenPRCount, []float64{5, 10, 15, 20}) {
+		t.Errorf("parseServerConfig: Unexpected open PR count thresholds %v", config.Policies.Thresholds.OpenPRCount)
+	}
+
+	// Settings missing from the file keep their default value.
+	if config.Provider.RateLimit != DefaultRateLimitPolicy.RequestsPerHour {
+		t.Errorf("parseServerConfig: Expected default rate limit, got %d", config.Provider.RateLimit)
+	}
+	if config.Policies.BusinessHours.WorkHours != "09:00-17:00" {
+		t.Errorf("parseServerConfig: Expected default work hours, got %s", config.Policies.BusinessHours.WorkHours)
+	}
+	if !reflect.DeepEqual(config.Policies.Thresholds.PRAge, DefaultThresholdPolicy().PRAge) {
+		t.Errorf("parseServerConfig: Expected default PR age thresholds, got %v", config.Policies.Thresholds.PRAge)
+	}
+
+	invalidFiles := []string{
+		"unknown: true",
+		"provider:\n  callTimeout: 10",
+		"listen:\n  port: http",
+	}
+	for _, file := range invalidFiles {
+		if _, err := parseServerConfig([]byte(file)); err == nil {
+			t.Errorf("parseServerConfig: Should generate an error for '%s'", file)
+		}
+	}
+}
+
+func TestLoadServerConfig(t *testing.T) {
+	file, err := ioutil.TempFile("", "bitbadger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	file.WriteString("listen:\n  adminPort: 9090\n")
+	file.Close()
+
+	config, err := LoadServerConfig(file.Name())
+	if err != nil {
+		t.Fatalf("LoadServerConfig: Unexpected error: %s", err)
+	}
+	if config.Listen.AdminPort != 9090 {
+		t.Errorf("LoadServerConfig: Expected admin port 9090, got %d", config.Listen.AdminPort)
+	}
+
+	if _, err := LoadServerConfig(file.Name() + ".missing"); err == nil {
+		t.Errorf("LoadServerConfig: Should generate an error for missing files")
+	}
+}
+
+func TestApplyEnvironment(t *testing.T) {
+	config := DefaultServerConfig()
+	err := config.ApplyEnvironment([]string{
+		"HOME=/root",
+		"BITBADGER_DEBUG=true",
+		"BITBADGER_CREDENTIALS_PASSWORD_FILE=/run/secrets/bitbucket",
+		"BITBADGER_PROVIDER_BREAKER_DURATION=1m",
+		"BITBADGER_LISTEN_ADMIN_PORT=9090",
+		"BITBADGER_LISTEN_TLS_CERT=cert.pem",
+		"BITBADGER_POLICIES_DRAFT_PATTERNS=^WIP, ^Draft",
+		"BITBADGER_POLICIES_BUSINESS_HOURS_WORK_DAYS=mon,tue",
+		"BITBADGER_UNKNOWN=1",
+		"BITBADGER_LISTEN=1",
+		"BITBADGER_SERVICE_HOST=10.0.0.1",
+		"BITBADGER_PORT=tcp://10.0.0.1:34000",
+		"BITBADGER_PORT_34000_TCP_PORT=34000",
+	})
+	if err != nil {
+		t.Fatalf("ApplyEnvironment: Unexpected error: %s", err)
+	}
+
+	if !config.Debug {
+		t.Errorf("ApplyEnvironment: Expected debug mode")
+	}
+	if config.Credentials.PasswordFile != "/run/secrets/bitbucket" {
+		t.Errorf("ApplyEnvironment: Unexpected password file %s", config.Credentials.PasswordFile)
+	}
+	if time.Duration(config.Provider.BreakerDuration) != time.Minute {
+		t.Errorf("ApplyEnvironment: Unexpected breaker duration %s", time.Duration(config.Provider.BreakerDuration))
+	}
+	if config.Listen.AdminPort != 9090 || config.Listen.TLS.Cert != "cert.pem" {
+		t.Errorf("ApplyEnvironment: Unexpected listeners %+v", config.Listen)
+	}
+	if len(config.Policies.DraftPatterns) != 2 || config.Policies.DraftPatterns[1] != "^Draft" {
+		t.Errorf("ApplyEnvironment: Unexpected draft patterns %v", config.Policies.DraftPatterns)
+	}
+	if config.Policies.BusinessHours.WorkDays != "mon,tue" {
+		t.Errorf("ApplyEnvironment: Unexpected work days %s", config.Policies.BusinessHours.WorkDays)
+	}
+
+	invalidVariables := []string{
+		"BITBADGER_LISTEN_PORT=http",
+		"BITBADGER_DEBUG=maybe",
+		"BITBADGER_CACHE_VALIDITY=10",
+	}
+	for _, variable := range invalidVariables {
+		config := DefaultServerConfig()
+		if err := config.ApplyEnvironment([]string{variable}); err == nil {
+			t.Errorf("ApplyEnvironment: Should generate an error for '%s'", variable)
+		}
+	}
+}
+
+func TestValidateServerConfig(t *testing.T) {
+	if err := validServerConfig().Validate(); err != nil {
+		t.Errorf("Validate: Unexpected error: %s", err)
+	}
+
+	cases := []struct {
+		update  func(config *ServerConfig)
+		problem string
+	}{
+		{func(config *ServerConfig) { config.Credentials.Username = "" }, "credentials.username"},
+		{func(config *ServerConfig) { config.Credentials.Password = "" }, "credentials.password"},
+		{func(config *ServerConfig) { config.Credentials.PasswordFile = "secret" }, "credentials.passwordFile"},
+		{func(config *ServerConfig) { config.Credentials.Auth = "kerberos" }, "credentials.auth"},
+		{func(config *ServerConfig) { config.Credentials.Auth = "oauth" }, "credentials.clientId"},
+		{func(config *ServerConfig) { config.Credentials.Auth = "oauth" }, "credentials.clientSecret"},
+		{func(config *ServerConfig) { config.Credentials.Auth = "access-token" }, "credentials.accessToken"},
+		{func(config *ServerConfig) { config.Provider.Type = "github" }, "provider.type"},
+		{func(config *ServerConfig) { config.Provider.Retries = -1 }, "provider.retries"},
+		{func(config *ServerConfig) { config.Listen.Port = 0 }, "listen.port"},
+		{func(config *ServerConfig) { config.Listen.AdminPort = config.Listen.Port }, "listen.adminPort"},
+		{func(config *ServerConfig) { config.Listen.Insecure = false }, "listen.tls.cert"},
+		{func(config *ServerConfig) { config.Cache.MaxCached = -1 }, "cache.maxCached"},
+		{func(config *ServerConfig) { config.Policies.Workers = 0 }, "policies.workers"},
+		{func(config *ServerConfig) { config.Policies.DurationFormat = "short" }, "policies.durationFormat"},
+		{func(config *ServerConfig) { config.Policies.BusinessHours.Timezone = "Mars" }, "policies.businessHours.timezone"},
+		{func(config *ServerConfig) { config.Policies.BusinessHours.WorkHours = "9-5" }, "policies.businessHours.workHours"},
+		{func(config *ServerConfig) { config.Policies.Thresholds.PRAge = []float64{24, 48} }, "policies.thresholds"},
+	}
+
+	for _, c := range cases {
+		config := validServerConfig()
+		c.update(&config)
+
+		err := config.Validate()
+		if err == nil {
+			t.Errorf("Validate: Should generate an error for %s", c.problem)
+		} else if !strings.Contains(err.Error(), c.problem) {
+			t.Errorf("Validate: Expected an error for %s, got '%s'", c.problem, err)
+		}
+	}
+
+	// All problems are reported at once.
+	config := validServerConfig()
+	config.Listen.Port = 0
+	config.Policies.Workers = 0
+	err := config.Validate()
+	if err == nil || !strings.Contains(err.Error(), "listen.port") || !strings.Contains(err.Error(), "policies.workers") {
+		t.Errorf("Validate: Expected all problems to be reported, got '%v'", err)
+	}
+}
+
+func TestApplyServerConfig(t *testing.T) {
+	file, err := ioutil.TempFile("", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	file.WriteString("azerty\n")
+	file.Close()
+
+	config := validServerConfig()
+	config.Credentials.Password = ""
+	config.Credentials.PasswordFile = file.Name()
+	config.Provider.Retries = 1
+	config.Listen.AdminPort = 9090
+	config.Policies.Workers = 3
+
+	options, err := config.Apply()
+	if err != nil {
+		t.Fatalf("Apply: Unexpected error: %s", err)
+	}
+
+	server := NewServer(options...)
+	if server.config.Username != "Robert" || server.config.Password != "azerty" {
+		t.Errorf("Apply: Unexpected credentials %+v", server.config)
+	}
+	if server.adminAddr != ":9090" {
+		t.Errorf("Apply: Unexpected admin address '%s'", server.adminAddr)
+	}
+	if server.retryPolicy.MaxRetries != 1 {
+		t.Errorf("Apply: Expected 1 retry, got %d", server.retryPolicy.MaxRetries)
+	}
+	bb, ok := server.routes[0].Provider.(*BBCloud)
+	if !ok || server.workspacePolicy.Workers != 3 || bb.workspacePolicy.Workers != 3 {
+		t.Errorf("Apply: Expected 3 workers for the server and its provider")
+	}
+	if GetRetryPolicy().MaxRetries != DefaultRetryPolicy.MaxRetries || GetWorkspacePolicy().Workers != DefaultWorkspaceWorkers {
+		t.Errorf("Apply: The global policies should be left untouched")
+	}
+
+	config.Credentials.PasswordFile = file.Name() + ".missing"
+	if _, err := config.Apply(); err == nil {
+		t.Errorf("Apply: Should generate an error for missing password files")
+	}
+
+	config = validServerConfig()
+	config.Credentials = CredentialsConfig{
+		Auth:             "oauth",
+		ClientID:         "consumer",
+		ClientSecretFile: file.Name(),
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate: Unexpected error: %s", err)
+	}
+
+	options, err = config.Apply()
+	if err != nil {
+		t.Fatalf("Apply: Unexpected error: %s", err)
+	}
+
+	server = NewServer(options...)
+	if server.config.Auth != OAuthAuth || server.config.ClientID != "consumer" || server.config.ClientSecret != "azerty" {
+		t.Errorf("Apply: Unexpected OAuth credentials %+v", server.config)
+	}
+}
+
+func TestServerConfigCredentialRoutes(t *testing.T) {
+	config, err := parseServerConfig([]byte(`
+listen:
+  insecure: true
+credentialRoutes:
+  - workspace: acme-*
+    credentials:
+      auth: access-token
+      accessToken: token
+  - workspace: partner
+    project: WEB
+    credentials:
+      username: Robert
+      password: azerty
+`))
+	if err != nil {
+		t.Fatalf("parseServerConfig: Unexpected error: %s", err)
+	}
+
+	// Credentials are optional with routes.
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate: Unexpected error: %s", err)
+	}
+
+	options, err := config.Apply()
+	if err != nil {
+		t.Fatalf("Apply: Unexpected error: %s", err)
+	}
+
+	server := NewServer(options...)
+	if len(server.routes) != 2 {
+		t.Fatalf("Apply: Expected 2 routes without default credentials, got %d", len(server.routes))
+	}
+	bb, ok := server.routes[0].Provider.(*BBCloud)
+	if !ok || server.routes[0].Workspace != "acme-*" || bb.config.Auth != AccessTokenAuth || bb.config.AccessToken != "token" {
+		t.Errorf("Apply: Unexpected route %+v", server.routes[0].CredentialRoute)
+	}
+	if server.routes[1].Workspace != "partner" || server.routes[1].Project != "WEB" {
+		t.Errorf("Apply: Unexpected route %+v", server.routes[1].CredentialRoute)
+	}
+	// Routes share the HTTP client and rate limiter of the server.
+	if bb.client != server.client || bb.rateLimiter != server.rateLimiter {
+		t.Errorf("Apply: Routes should share the client and rate limiter of the server")
+	}
+
+	cases := []struct {
+		route   CredentialRouteConfig
+		problem string
+	}{
+		{CredentialRouteConfig{Workspace: "acme"}, "credentialRoutes[0].credentials.username"},
+		{CredentialRouteConfig{Credentials: CredentialsConfig{AccessToken: "token", Auth: "access-token"}}, "credentialRoutes[0].workspace"},
+		{CredentialRouteConfig{Workspace: "[acme", Credentials: CredentialsConfig{AccessToken: "token", Auth: "access-token"}}, "credentialRoutes[0] has an invalid pattern"},
+		{CredentialRouteConfig{Workspace: "acme", Provider: "github", Credentials: CredentialsConfig{AccessToken: "token", Auth: "access-token"}}, "credentialRoutes[0].provider"},
+	}
+
+	for _, c := range cases {
+		config := validServerConfig()
+		config.CredentialRoutes = []CredentialRouteConfig{c.route}
+
+		err := config.Validate()
+		if err == nil {
+			t.Errorf("Validate: Should generate an error for %s", c.problem)
+		} else if !strings.Contains(err.Error(), c.problem) {
+			t.Errorf("Validate: Expected an error for %s, got '%s'", c.problem, err)
+		}
+	}
+}
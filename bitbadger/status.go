@@ -0,0 +1,124 @@
+package bitbadger
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// StatusPath is the path of the server status. Badge paths have at least
+// three elements, so it can't collide with a badge request.
+const StatusPath = "/status"
+
+// ServerStatus holds the state of the circuit breakers of a server.
+type ServerStatus struct {
+	Providers []CircuitBreakerStatus
+}
+
+// Status returns the current state of the server.
+func (server *Server) Status() ServerStatus {
+	status := ServerStatus{Providers: []CircuitBreakerStatus{}}
+	for _, route := range server.routes {
+		status.Providers = append(status.Providers, route.guarded.breaker.Status())
+	}
+
+	return status
+}
+
+// serveStatus sends the server status as JSON.
+func (server *Server) serveStatus(w http.ResponseWriter) {
+	data, err := json.Marshal(server.Status())
+	if err != nil {
+		log.Error("Failed to encode status: ", err)
+		http.Error(w, "Failed to encode status", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// serveUnavailable responds to a request while the circuit of the provider is
+// open. It sends the last cached result even if no longer valid, or else an
+// "unavailable" badge. Neither is cached.
+func (server *Server) serveUnavailable(ctx context.Context, w http.ResponseWriter, request BadgeRequest) {
+	route := server.routes.route(request)
+	if status := route.guarded.breaker.Status(); status.RetryAt != nil {
+		retryAfter := time.Until(*status.RetryAt)/time.Second + 1
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter)))
+	}
+
+	if staleImage := server.cache.GetStale(request); staleImage != nil {
+		log.Info("Upstream unavailable, serving stale badge for ", request.Username, "/", request.Repository, "/", request.Type)
+		w.Header().Set("Warning", `110 - "Response is Stale"`)
+		sendHTTPReponse(w, staleImage)
+		return
+	}
+
+	if request.JSON {
+		http.Error(w, ErrCircuitOpen.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	badgeImage, err := server.renderer.Render(ctx, unavailableBadgeInfo(request, server.requestLocalizer(request)).Segments())
+	if err != nil {
+		log.Error("Error rendering badge: ", err)
+		http.Error(w, ErrCircuitOpen.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	sendHTTPReponse(w, badgeImage)
+}
+
+// unavailableBadgeInfo returns the badge shown when the upstream server is
+// unavailable.
+func unavailableBadgeInfo(request BadgeRequest, localizer Localizer) BadgeInfo {
+	label := request.Label
+	if label == "" {
+		label = localizer.Text("label.upstream")
+	}
+
+	return BadgeInfo{
+		Label:   label,
+		Message: localizer.Text("message.unavailable"),
+		Color:   "lightgrey",
+	}
+}
+
+// serveNoCredentials responds to a request whose workspace matches no
+// credential route, with an error badge. It is not cached, so that the badge
+// is served once credentials are configured.
+func (server *Server) serveNoCredentials(ctx context.Context, w http.ResponseWriter, request BadgeRequest) {
+	if request.JSON {
+		http.Error(w, ErrNoCredentials.Error(), http.StatusForbidden)
+		return
+	}
+
+	badgeImage, err := server.renderer.Render(ctx, noCredentialsBadgeInfo(request, server.requestLocalizer(request)).Segments())
+	if err != nil {
+		log.Error("Error rendering badge: ", err)
+		http.Error(w, ErrNoCredentials.Error(), http.StatusForbidden)
+		return
+	}
+
+	sendHTTPReponse(w, badgeImage)
+}
+
+// noCredentialsBadgeInfo returns the badge shown when no credentials are
+// configured for the workspace of the request.
+func noCredentialsBadgeInfo(request BadgeRequest, localizer Localizer) BadgeInfo {
+	label := request.Label
+	if label == "" {
+		label = localizer.Text("label.upstream")
+	}
+
+	return BadgeInfo{
+		Label:   label,
+		Message: localizer.Text("message.no-credentials"),
+		Color:   "red",
+	}
+}
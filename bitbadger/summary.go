@@ -0,0 +1,136 @@
+package bitbadger
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// DefaultSummaryParts lists the badge types shown by a summary badge, unless
+// the request chooses them.
+var DefaultSummaryParts = []BadgeType{
+	OpenPRCountType,
+	OldestOpenPRAge,
+	AveragePRMergeTime,
+}
+
+// SummaryInfo holds the badges and metrics of each part of a summary badge.
+type SummaryInfo struct {
+	Label string
+	Parts []MetricsReport
+}
+
+// Segments returns the segments of the summary badge: the label, followed by
+// one segment for each part.
+func (summaryInfo SummaryInfo) Segments() []BadgeSegment {
+	segments := []BadgeSegment{{
+		Text:  summaryInfo.Label,
+		Color: labelColor,
+	}}
+
+	for _, part := range summaryInfo.Parts {
+		segments = append(segments, BadgeSegment{
+			Text:  part.Badge.Message,
+			Color: part.Badge.Color,
+		})
+	}
+
+	return segments
+}
+
+// parseSummaryParts parses a comma separated list of badge types to show in
+// a summary badge.
+func parseSummaryParts(show string) ([]BadgeType, error) {
+	if show == "" {
+		return DefaultSummaryParts, nil
+	}
+
+	parts := []BadgeType{}
+	for _, part := range strings.Split(show, ",") {
+		badgeType, err := GetBadgeType(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		if badgeType == SummaryType {
+			return nil, errors.New("A summary can't show another summary")
+		}
+
+		parts = append(parts, badgeType)
+	}
+
+	return parts, nil
+}
+
+// retrieveSummaryInfo retrieves the metrics of each part of a summary badge,
+// and generates their badge information. Pull request information is only
+// retrieved once, as most parts are derived from it.
+func (server *Server) retrieveSummaryInfo(ctx context.Context, request BadgeRequest) (SummaryInfo, error) {
+	parts, err := parseSummaryParts(request.Show)
+	if err != nil {
+		return SummaryInfo{}, err
+	}
+
+	summaryInfo := SummaryInfo{
+		Label: request.Label,
+		Parts: []MetricsReport{},
+	}
+	if summaryInfo.Label == "" {
+		summaryInfo.Label = request.Repository
+	}
+
+	var prInfo *PullRequestsInfo
+	for _, part := range parts {
+		partRequest := request
+		partRequest.Type = part
+		partRequest.Show = ""
+
+		var metrics interface{}
+		if metricsKind(part) == "pull request" {
+			if prInfo == nil {
+				// The summary request selects the merged pull request
+				// history needed by its parts.
+				info, err := server.retrievePullRequestInfo(ctx, request)
+				if err != nil {
+					return SummaryInfo{}, metricsError("pull request", err)
+				}
+
+				prInfo = &info
+			}
+
+			metrics = server.pullRequestsMetrics(part, *prInfo)
+		} else {
+			metrics, err = server.RetrieveMetrics(ctx, partRequest)
+			if err != nil {
+				return SummaryInfo{}, err
+			}
+		}
+
+		localizer := server.requestLocalizer(request)
+		badge, err := checkBadgeInfo(server.factory.metricsBadgeInfo(part, metrics, localizer))
+		if err != nil {
+			return SummaryInfo{}, err
+		}
+
+		summaryInfo.Parts = append(summaryInfo.Parts, MetricsReport{
+			Badge:   summaryPartBadge(part, badge, localizer),
+			Metrics: metrics,
+		})
+	}
+
+	return summaryInfo, nil
+}
+
+// summaryPartBadge shortens the badge of a summary part, prefixing its message
+// with a short label.
+func summaryPartBadge(badgeType BadgeType, badge BadgeInfo, localizer Localizer) BadgeInfo {
+	shortLabel := strings.ToLower(badge.Label)
+	if _, found := englishCatalog["summary."+string(badgeType)]; found {
+		shortLabel = localizer.Text("summary." + string(badgeType))
+	}
+
+	return BadgeInfo{
+		Label:   badge.Label,
+		Message: shortLabel + " " + badge.Message,
+		Color:   badge.Color,
+	}
+}
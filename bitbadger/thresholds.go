@@ -0,0 +1,134 @@
+package bitbadger
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// ThresholdPolicy holds the values at which pull request and commit badges
+// change color. Each list holds the limits of the green, yellowgreen, yellow
+// and orange colors, and worse values are red. Counts and durations are
+// better when lower: a count color applies up to and including its limit, and
+// a duration color up to its limit only. Rates are better when higher, and a
+// color applies from its limit, or above it when the limit is 0 so that no
+// activity at all is red. Durations are expressed in hours, and rates per
+// week.
+type ThresholdPolicy struct {
+	// OpenPRCount colors open-pr-count.
+	OpenPRCount []float64 `yaml:"openPRCount"`
+	// PRAge colors open-pr-avg-age, oldest-open-pr-age and avg-pr-merge-time.
+	PRAge []float64 `yaml:"prAge"`
+	// ReviewCount colors awaiting-review-count and pending-reviews.
+	ReviewCount []float64 `yaml:"reviewCount"`
+	// Reviewers colors avg-reviewers-per-pr.
+	Reviewers []float64 `yaml:"reviewers"`
+	// MergedPRs colors the merged-prs badges, whatever their unit.
+	MergedPRs []float64 `yaml:"mergedPRs"`
+	// CommitActivity colors commit-activity.
+	CommitActivity []float64 `yaml:"commitActivity"`
+}
+
+// thresholdColors are the colors of badges within each threshold, from the
+// best to the worst.
+var thresholdColors = []string{"green", "yellowgreen", "yellow", "orange"}
+
+// DefaultThresholdPolicy returns the threshold policy used when none is
+// configured.
+func DefaultThresholdPolicy() ThresholdPolicy {
+	return ThresholdPolicy{
+		OpenPRCount:    []float64{3, 5, 7, 9},
+		PRAge:          []float64{24, 48, 72, 96},
+		ReviewCount:    []float64{0, 2, 4, 6},
+		Reviewers:      []float64{2, 1.5, 1, 0},
+		MergedPRs:      []float64{10, 5, 2, 1},
+		CommitActivity: []float64{10, 5, 1, 0},
+	}
+}
+
+var thresholdPolicy = DefaultThresholdPolicy()
+
+// SetThresholdPolicy sets the global threshold policy. It returns an error if
+// the policy is not valid, in which case the current policy is left
+// untouched.
+func SetThresholdPolicy(policy ThresholdPolicy) error {
+	err := policy.Validate()
+	if err != nil {
+		return err
+	}
+
+	thresholdPolicy = policy
+	return nil
+}
+
+// GetThresholdPolicy returns the current global threshold policy.
+func GetThresholdPolicy() ThresholdPolicy {
+	return thresholdPolicy
+}
+
+// Validate returns an error unless each list has a limit for each color,
+// from the best to the worst.
+func (policy ThresholdPolicy) Validate() error {
+	thresholds := []struct {
+		name           string
+		limits         []float64
+		higherIsBetter bool
+	}{
+		{"openPRCount", policy.OpenPRCount, false},
+		{"prAge", policy.PRAge, false},
+		{"reviewCount", policy.ReviewCount, false},
+		{"reviewers", policy.Reviewers, true},
+		{"mergedPRs", policy.MergedPRs, true},
+		{"commitActivity", policy.CommitActivity, true},
+	}
+
+	for _, threshold := range thresholds {
+		if len(threshold.limits) != len(thresholdColors) {
+			return errors.New("Thresholds '" + threshold.name + "' require " +
+				strconv.Itoa(len(thresholdColors)) + " values")
+		}
+
+		for i := 1; i < len(threshold.limits); i++ {
+			previous, limit := threshold.limits[i-1], threshold.limits[i]
+			if threshold.higherIsBetter && limit > previous || !threshold.higherIsBetter && limit < previous {
+				return errors.New("Thresholds '" + threshold.name + "' must go from the best to the worst value")
+			}
+		}
+	}
+
+	return nil
+}
+
+// countColor returns the color of count, given the limits of each color.
+func countColor(count int, limits []float64) string {
+	for i, limit := range limits {
+		if float64(count) <= limit {
+			return thresholdColors[i]
+		}
+	}
+
+	return "red"
+}
+
+// durationColor returns the color of duration, given the limits of each color
+// in hours.
+func durationColor(duration time.Duration, limits []float64) string {
+	for i, limit := range limits {
+		if duration.Hours() < limit {
+			return thresholdColors[i]
+		}
+	}
+
+	return "red"
+}
+
+// rateColor returns the color of rate, given the limits of each color.
+func rateColor(rate float64, limits []float64) string {
+	for i, limit := range limits {
+		if rate >= limit && (limit > 0 || rate > 0) {
+			return thresholdColors[i]
+		}
+	}
+
+	return "red"
+}
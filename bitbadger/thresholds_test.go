@@ -0,0 +1,126 @@
+package bitbadger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestThresholdColors(t *testing.T) {
+	limits := []float64{3, 5, 7, 9}
+	countCases := []struct {
+		count    int
+		expected string
+	}{
+		{0, "green"},
+		{3, "green"},
+		{4, "yellowgreen"},
+		{9, "orange"},
+		{10, "red"},
+	}
+	for _, c := range countCases {
+		if color := countColor(c.count, limits); color != c.expected {
+			t.Errorf("countColor: Expected %s for %d, got %s", c.expected, c.count, color)
+		}
+	}
+
+	durationCases := []struct {
+		duration time.Duration
+		expected string
+	}{
+		{0, "green"},
+		{3*time.Hour - time.Second, "green"},
+		{3 * time.Hour, "yellowgreen"},
+		{9 * time.Hour, "red"},
+	}
+	for _, c := range durationCases {
+		if color := durationColor(c.duration, limits); color != c.expected {
+			t.Errorf("durationColor: Expected %s for %s, got %s", c.expected, c.duration, color)
+		}
+	}
+
+	rateCases := []struct {
+		rate     float64
+		limits   []float64
+		expected string
+	}{
+		{10, []float64{10, 9, 5, 3}, "green"},
+		{9, []float64{10, 9, 5, 3}, "yellowgreen"},
+		{2.9, []float64{10, 9, 5, 3}, "red"},
+		{0.05, []float64{10, 5, 1, 0}, "orange"},
+		{0, []float64{10, 5, 1, 0}, "red"},
+		{0, []float64{10, 5, 0, 0}, "red"},
+	}
+	for _, c := range rateCases {
+		if color := rateColor(c.rate, c.limits); color != c.expected {
+			t.Errorf("rateColor: Expected %s for %f, got %s", c.expected, c.rate, color)
+		}
+	}
+}
+
+func TestDefaultThresholdBounds(t *testing.T) {
+	cases := []struct {
+		badgeType   BadgeType
+		prInfo      PullRequestsInfo
+		commitsInfo CommitsInfo
+		expected    string
+	}{
+		{OldestOpenPRAge, PullRequestsInfo{OldestOpenPR: 24*time.Hour - time.Minute}, CommitsInfo{}, "green"},
+		{OldestOpenPRAge, PullRequestsInfo{OldestOpenPR: 24 * time.Hour}, CommitsInfo{}, "yellowgreen"},
+		{AveragePRMergeTime, PullRequestsInfo{AveragePRMergeTime: 96 * time.Hour}, CommitsInfo{}, "red"},
+		{OpenPRCountType, PullRequestsInfo{OpenCount: 3}, CommitsInfo{}, "green"},
+		{AverageReviewersType, PullRequestsInfo{AverageReviewers: 0.05}, CommitsInfo{}, "orange"},
+		{AverageReviewersType, PullRequestsInfo{}, CommitsInfo{}, "red"},
+		{CommitActivityType, PullRequestsInfo{}, CommitsInfo{CommitCount: 1, Window: 20 * 7 * 24 * time.Hour}, "orange"},
+		{CommitActivityType, PullRequestsInfo{}, CommitsInfo{Window: 7 * 24 * time.Hour}, "red"},
+	}
+
+	for _, c := range cases {
+		var badgeInfo BadgeInfo
+		if c.badgeType == CommitActivityType {
+			badgeInfo, _ = GenerateCommitsBadgeInfo(c.badgeType, c.commitsInfo, DefaultLocalizer())
+		} else {
+			badgeInfo, _ = GenerateBadgeInfo(c.badgeType, c.prInfo)
+		}
+
+		if badgeInfo.Color != c.expected {
+			t.Errorf("GenerateBadgeInfo: Expected %s for %s, got %s", c.expected, c.badgeType, badgeInfo.Color)
+		}
+	}
+}
+
+func TestSetThresholdPolicy(t *testing.T) {
+	defer SetThresholdPolicy(GetThresholdPolicy())
+
+	policy := DefaultThresholdPolicy()
+	policy.OpenPRCount = []float64{10, 20, 30, 40}
+	policy.PRAge = []float64{1, 2, 3, 4}
+	if err := SetThresholdPolicy(policy); err != nil {
+		t.Fatalf("SetThresholdPolicy: Unexpected error: %s", err)
+	}
+
+	badgeInfo, _ := GenerateBadgeInfo(OpenPRCountType, PullRequestsInfo{OpenCount: 12})
+	if badgeInfo.Color != "yellowgreen" {
+		t.Errorf("GenerateBadgeInfo: Expected the configured open PR count color, got %s", badgeInfo.Color)
+	}
+	badgeInfo, _ = GenerateBadgeInfo(OldestOpenPRAge, PullRequestsInfo{OldestOpenPR: 150 * time.Minute})
+	if badgeInfo.Color != "yellow" {
+		t.Errorf("GenerateBadgeInfo: Expected the configured PR age color, got %s", badgeInfo.Color)
+	}
+
+	invalidPolicies := []func(policy *ThresholdPolicy){
+		func(policy *ThresholdPolicy) { policy.OpenPRCount = nil },
+		func(policy *ThresholdPolicy) { policy.ReviewCount = []float64{0, 1, 2, 3, 4} },
+		func(policy *ThresholdPolicy) { policy.PRAge = []float64{48, 24, 72, 96} },
+		func(policy *ThresholdPolicy) { policy.MergedPRs = []float64{1, 2, 5, 10} },
+	}
+	for i, update := range invalidPolicies {
+		invalidPolicy := DefaultThresholdPolicy()
+		update(&invalidPolicy)
+		if err := SetThresholdPolicy(invalidPolicy); err == nil {
+			t.Errorf("SetThresholdPolicy: Should generate an error for case %d", i)
+		}
+	}
+	if GetThresholdPolicy().OpenPRCount[0] != 10 {
+		t.Errorf("SetThresholdPolicy: Invalid policies should leave the current policy untouched")
+	}
+}
@@ -0,0 +1,67 @@
+package bitbadger
+
+import (
+	"strings"
+	"time"
+)
+
+// ThroughputPolicy holds the settings used to compute merged PR throughput.
+type ThroughputPolicy struct {
+	// Window is the rolling window over which merged PRs are counted, unless
+	// overridden by the request.
+	Window time.Duration
+}
+
+// DefaultThroughputWindow is the default rolling window used to compute
+// throughput metrics.
+const DefaultThroughputWindow = 28 * 24 * time.Hour
+
+var throughputPolicy = ThroughputPolicy{
+	Window: DefaultThroughputWindow,
+}
+
+// SetThroughputPolicy sets the global throughput policy.
+func SetThroughputPolicy(policy ThroughputPolicy) {
+	throughputPolicy = policy
+}
+
+// GetThroughputPolicy returns the current global throughput policy.
+func GetThroughputPolicy() ThroughputPolicy {
+	return throughputPolicy
+}
+
+// requestWindow returns the throughput window to use for request, which can
+// override the policy window with a number of days.
+func (policy ThroughputPolicy) requestWindow(request BadgeRequest) time.Duration {
+	if request.Days > 0 {
+		return time.Duration(request.Days) * 24 * time.Hour
+	}
+
+	if policy.Window <= 0 {
+		return DefaultThroughputWindow
+	}
+
+	return policy.Window
+}
+
+// throughputRate returns the number of events per period, given count events
+// happened over window.
+func throughputRate(count int, window time.Duration, period time.Duration) float64 {
+	if window <= 0 {
+		return 0
+	}
+
+	return float64(count) * float64(period) / float64(window)
+}
+
+// printRate prints a rate compactly, with a single decimal for small rates,
+// followed by its localized unit. For instance "2.5/week" or "12/day".
+func printRate(rate float64, unit string, localizer Localizer) string {
+	precision := 1
+	if rate >= 10 {
+		precision = 0
+	}
+
+	amount := strings.TrimSuffix(localizer.Number(rate, precision), localizer.Text("number.decimal")+"0")
+	return localizer.Text("message.rate", amount, localizer.Text("unit."+unit))
+}
@@ -0,0 +1,52 @@
+package bitbadger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRequestThroughputWindow(t *testing.T) {
+	defer SetThroughputPolicy(ThroughputPolicy{Window: DefaultThroughputWindow})
+
+	SetThroughputPolicy(ThroughputPolicy{Window: 14 * 24 * time.Hour})
+	if GetThroughputPolicy().Window != 14*24*time.Hour {
+		t.Errorf("Set/GetThroughputPolicy: Throughput policies differ")
+	}
+
+	if GetThroughputPolicy().requestWindow(BadgeRequest{}) != 14*24*time.Hour {
+		t.Errorf("requestWindow: Should use the policy window")
+	}
+	if GetThroughputPolicy().requestWindow(BadgeRequest{Days: 7}) != 7*24*time.Hour {
+		t.Errorf("requestWindow: Should use the request number of days")
+	}
+
+	SetThroughputPolicy(ThroughputPolicy{})
+	if GetThroughputPolicy().requestWindow(BadgeRequest{}) != DefaultThroughputWindow {
+		t.Errorf("requestWindow: Should fall back to the default window")
+	}
+}
+
+func TestPrintRate(t *testing.T) {
+	week := 7 * 24 * time.Hour
+	cases := []struct {
+		count    int
+		window   time.Duration
+		period   time.Duration
+		unit     string
+		expected string
+	}{
+		{0, 4 * week, week, "week", "0/week"},
+		{10, 4 * week, week, "week", "2.5/week"},
+		{8, 4 * week, week, "week", "2/week"},
+		{50, week, week, "week", "50/week"},
+		{1, week, 24 * time.Hour, "day", "0.1/day"},
+		{3, 0, week, "week", "0/week"},
+	}
+
+	for _, c := range cases {
+		rate := printRate(throughputRate(c.count, c.window, c.period), c.unit, DefaultLocalizer())
+		if rate != c.expected {
+			t.Errorf("printRate: Expected '%s' for %d over %s, got '%s'", c.expected, c.count, c.window, rate)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package bitbadger
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// UpstreamErrorKind classifies the errors returned by the upstream repository.
+type UpstreamErrorKind int
+
+// Kinds of upstream errors.
+const (
+	// UpstreamUnexpected is any unexpected response.
+	UpstreamUnexpected UpstreamErrorKind = iota
+	// UpstreamNotFound is returned for unknown workspaces, repositories or
+	// resources.
+	UpstreamNotFound
+	// UpstreamUnauthorized is returned when the credentials are invalid or
+	// lack the permission to access a resource.
+	UpstreamUnauthorized
+	// UpstreamRateLimited is returned when the API quota of the account is
+	// exhausted.
+	UpstreamRateLimited
+	// UpstreamServerError is returned when the upstream server fails.
+	UpstreamServerError
+)
+
+func (kind UpstreamErrorKind) String() string {
+	switch kind {
+	case UpstreamNotFound:
+		return "not found"
+	case UpstreamUnauthorized:
+		return "unauthorized"
+	case UpstreamRateLimited:
+		return "rate limited"
+	case UpstreamServerError:
+		return "server error"
+	default:
+		return "unexpected response"
+	}
+}
+
+// UpstreamError is returned when the upstream repository responds with an
+// error.
+type UpstreamError struct {
+	Kind UpstreamErrorKind
+	// StatusCode is the HTTP status of the response, or zero if the request
+	// was not sent.
+	StatusCode int
+	// RetryAfter is how long to wait before retrying, if known.
+	RetryAfter time.Duration
+}
+
+func (err *UpstreamError) Error() string {
+	if err.StatusCode == 0 {
+		return "Upstream server error: " + err.Kind.String()
+	}
+
+	return fmt.Sprintf("Upstream server error: %s (%d %s)",
+		err.Kind, err.StatusCode, http.StatusText(err.StatusCode))
+}
+
+// Temporary returns true if the request may succeed when retried.
+func (err *UpstreamError) Temporary() bool {
+	return err.Kind == UpstreamRateLimited || err.Kind == UpstreamServerError
+}
+
+// newUpstreamError returns the error matching a non-200 response.
+func newUpstreamError(resp *http.Response) *UpstreamError {
+	err := &UpstreamError{
+		Kind:       UpstreamUnexpected,
+		StatusCode: resp.StatusCode,
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		err.Kind = UpstreamNotFound
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		err.Kind = UpstreamUnauthorized
+	case resp.StatusCode == http.StatusTooManyRequests:
+		err.Kind = UpstreamRateLimited
+	case resp.StatusCode >= 500:
+		err.Kind = UpstreamServerError
+	}
+
+	if retryAfter, valid := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); valid {
+		err.RetryAfter = retryAfter
+	}
+
+	return err
+}
+
+// parseRetryAfter parses a Retry-After header, either in seconds or as an HTTP
+// date, and returns the duration to wait from now.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if date.Before(now) {
+		return 0, true
+	}
+
+	return date.Sub(now), true
+}
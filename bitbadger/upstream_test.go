@@ -0,0 +1,61 @@
+package bitbadger
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewUpstreamError(t *testing.T) {
+	cases := []struct {
+		status     int
+		retryAfter string
+		kind       UpstreamErrorKind
+		temporary  bool
+		delay      time.Duration
+	}{
+		{http.StatusNotFound, "", UpstreamNotFound, false, 0},
+		{http.StatusUnauthorized, "", UpstreamUnauthorized, false, 0},
+		{http.StatusForbidden, "", UpstreamUnauthorized, false, 0},
+		{http.StatusTooManyRequests, "120", UpstreamRateLimited, true, 2 * time.Minute},
+		{http.StatusServiceUnavailable, "3", UpstreamServerError, true, 3 * time.Second},
+		{http.StatusInternalServerError, "", UpstreamServerError, true, 0},
+		{http.StatusBadRequest, "", UpstreamUnexpected, false, 0},
+	}
+
+	for _, c := range cases {
+		resp := &http.Response{StatusCode: c.status, Header: http.Header{}}
+		if c.retryAfter != "" {
+			resp.Header.Set("Retry-After", c.retryAfter)
+		}
+
+		err := newUpstreamError(resp)
+		if err.Kind != c.kind || err.Temporary() != c.temporary || err.RetryAfter != c.delay {
+			t.Errorf("newUpstreamError: Unexpected error %+v for status %d", err, c.status)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2020, 12, 14, 10, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		value    string
+		expected time.Duration
+		valid    bool
+	}{
+		{"", 0, false},
+		{"30", 30 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"Mon, 14 Dec 2020 10:01:30 GMT", 90 * time.Second, true},
+		{"Mon, 14 Dec 2020 09:00:00 GMT", 0, true},
+	}
+
+	for _, c := range cases {
+		delay, valid := parseRetryAfter(c.value, now)
+		if delay != c.expected || valid != c.valid {
+			t.Errorf("parseRetryAfter: Expected %v, %v for '%s', got %v, %v", c.expected, c.valid, c.value, delay, valid)
+		}
+	}
+}
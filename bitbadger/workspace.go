@@ -0,0 +1,215 @@
+package bitbadger
+
+import (
+	"context"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// WorkspacePolicy holds the settings used to aggregate the metrics of all the
+// repositories of a workspace.
+type WorkspacePolicy struct {
+	// Workers is the maximum number of repositories queried concurrently.
+	Workers int
+}
+
+// DefaultWorkspaceWorkers is the default number of repositories queried
+// concurrently.
+const DefaultWorkspaceWorkers = 4
+
+var workspacePolicy = WorkspacePolicy{
+	Workers: DefaultWorkspaceWorkers,
+}
+
+// SetWorkspacePolicy sets the global workspace policy.
+func SetWorkspacePolicy(policy WorkspacePolicy) {
+	workspacePolicy = policy
+}
+
+// GetWorkspacePolicy returns the current global workspace policy.
+func GetWorkspacePolicy() WorkspacePolicy {
+	return workspacePolicy
+}
+
+// retrieveWorkspacePullRequestInfo retrieves the pull request information of
+// all the repositories of the request workspace matching its include and
+// exclude patterns, and aggregates it.
+func (server *Server) retrieveWorkspacePullRequestInfo(ctx context.Context, request BadgeRequest) (PullRequestsInfo, error) {
+	repositories, err := server.provider.RetrieveRepositories(ctx, request)
+	if err != nil {
+		return PullRequestsInfo{}, err
+	}
+
+	repositories = filterRepositories(repositories, request.Include, request.Exclude)
+	log.Debug("Aggregating ", len(repositories), " repositories of ", request.Username)
+
+	prInfos := make([]PullRequestsInfo, len(repositories))
+	errs := make([]error, len(repositories))
+	err = server.workspacePolicy.runWorkers(ctx, len(repositories), func(i int) {
+		repositoryRequest := request
+		repositoryRequest.Repository = repositories[i]
+		prInfos[i], errs[i] = server.provider.RetrievePullRequestInfo(ctx, repositoryRequest)
+	})
+	if err != nil {
+		return PullRequestsInfo{}, err
+	}
+
+	// Repositories which can't be seen, such as repositories deleted since
+	// they were listed, are left out. Other errors, such as outages, fail the
+	// badge rather than showing partial metrics.
+	retrieved := []PullRequestsInfo{}
+	var skippedErr error
+	for i, err := range errs {
+		if err == nil {
+			retrieved = append(retrieved, prInfos[i])
+			continue
+		}
+
+		if !skippableRepositoryError(err) {
+			log.Error("Failed to retrieve pull request info of ", repositories[i], ": ", err)
+			return PullRequestsInfo{}, err
+		}
+
+		log.Warn("Leaving ", repositories[i], " out of the workspace badge: ", err)
+		workspaceSkippedTotal.add(1, errorKindLabel(err))
+		skippedErr = err
+	}
+
+	// Credentials which can see no repository are more likely revoked.
+	if len(retrieved) == 0 && skippedErr != nil {
+		return PullRequestsInfo{}, skippedErr
+	}
+
+	return aggregatePullRequestsInfo(retrieved), nil
+}
+
+// runWorkers calls job for each index in [0, count), running at most the
+// number of workers of the policy concurrently. It stops dispatching jobs and
+// returns the context error once ctx is cancelled.
+func (policy WorkspacePolicy) runWorkers(ctx context.Context, count int, job func(i int)) error {
+	workers := policy.Workers
+	if workers <= 0 {
+		workers = DefaultWorkspaceWorkers
+	}
+	if workers > count {
+		workers = count
+	}
+
+	jobs := make(chan int)
+
+	var waitGroup sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for i := range jobs {
+				job(i)
+			}
+		}()
+	}
+
+	// Stop dispatching jobs once the request is cancelled.
+dispatch:
+	for i := 0; i < count; i++ {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	waitGroup.Wait()
+
+	return ctx.Err()
+}
+
+// skippableRepositoryError returns true if err only prevents the retrieval of
+// a single repository of a workspace.
+func skippableRepositoryError(err error) bool {
+	upstreamErr, ok := err.(*UpstreamError)
+	return ok && (upstreamErr.Kind == UpstreamNotFound || upstreamErr.Kind == UpstreamUnauthorized)
+}
+
+// filterRepositories returns the repositories matching any of the comma
+// separated include patterns, if any, and none of the exclude patterns.
+// Patterns are validated by validateRepositoryPatterns.
+func filterRepositories(repositories []string, include, exclude string) []string {
+	filtered := []string{}
+	for _, repository := range repositories {
+		if include != "" && !matchesAnyPattern(repository, include) {
+			continue
+		}
+		if exclude != "" && matchesAnyPattern(repository, exclude) {
+			continue
+		}
+
+		filtered = append(filtered, repository)
+	}
+
+	return filtered
+}
+
+func matchesAnyPattern(name, patterns string) bool {
+	for _, pattern := range strings.Split(patterns, ",") {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateRepositoryPatterns returns an error if any of the comma separated
+// patterns is malformed.
+func validateRepositoryPatterns(patterns string) error {
+	for _, pattern := range strings.Split(patterns, ",") {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// aggregatePullRequestsInfo combines the pull request information of several
+// repositories. Counts are summed, maximums are kept, and averages are
+// weighted by the number of pull requests they were computed from.
+func aggregatePullRequestsInfo(prInfos []PullRequestsInfo) PullRequestsInfo {
+	var aggregated PullRequestsInfo
+	var openTimeTotal, mergeTimeTotal time.Duration
+	var reviewersTotal float64
+
+	for _, prInfo := range prInfos {
+		aggregated.OpenCount += prInfo.OpenCount
+		aggregated.DraftCount += prInfo.DraftCount
+		aggregated.AwaitingReviewCount += prInfo.AwaitingReviewCount
+		aggregated.PendingReviewCount += prInfo.PendingReviewCount
+		aggregated.MergedCount += prInfo.MergedCount
+		aggregated.MergeTimeSamples += prInfo.MergeTimeSamples
+
+		if prInfo.OldestOpenPR > aggregated.OldestOpenPR {
+			aggregated.OldestOpenPR = prInfo.OldestOpenPR
+		}
+		if prInfo.MergedWindow > aggregated.MergedWindow {
+			aggregated.MergedWindow = prInfo.MergedWindow
+		}
+
+		openTimeTotal += prInfo.OpenAverageTime * time.Duration(prInfo.OpenCount)
+		mergeTimeTotal += prInfo.AveragePRMergeTime * time.Duration(prInfo.MergeTimeSamples)
+		reviewersTotal += prInfo.AverageReviewers * float64(prInfo.OpenCount)
+	}
+
+	if aggregated.OpenCount > 0 {
+		aggregated.OpenAverageTime = openTimeTotal / time.Duration(aggregated.OpenCount)
+		aggregated.AverageReviewers = reviewersTotal / float64(aggregated.OpenCount)
+	}
+	if aggregated.MergeTimeSamples > 0 {
+		aggregated.AveragePRMergeTime = mergeTimeTotal / time.Duration(aggregated.MergeTimeSamples)
+	}
+
+	return aggregated
+}
@@ -0,0 +1,130 @@
+package bitbadger
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFilterRepositories(t *testing.T) {
+	repositories := []string{"api-users", "api-legacy", "web", "tools"}
+
+	cases := []struct {
+		include  string
+		exclude  string
+		expected []string
+	}{
+		{"", "", repositories},
+		{"api-*", "", []string{"api-users", "api-legacy"}},
+		{"api-*,web", "*-legacy", []string{"api-users", "web"}},
+		{"", "tools", []string{"api-users", "api-legacy", "web"}},
+		{"none", "", []string{}},
+	}
+
+	for _, c := range cases {
+		filtered := filterRepositories(repositories, c.include, c.exclude)
+		if !reflect.DeepEqual(filtered, c.expected) {
+			t.Errorf("filterRepositories: Expected %v for include '%s' and exclude '%s', got %v",
+				c.expected, c.include, c.exclude, filtered)
+		}
+	}
+
+	if validateRepositoryPatterns("api-*,web") != nil {
+		t.Errorf("validateRepositoryPatterns: Should accept valid patterns")
+	}
+	if validateRepositoryPatterns("api-*,[web") == nil {
+		t.Errorf("validateRepositoryPatterns: Should reject malformed patterns")
+	}
+}
+
+func TestAggregatePullRequestsInfo(t *testing.T) {
+	aggregated := aggregatePullRequestsInfo([]PullRequestsInfo{
+		{
+			OpenCount:          1,
+			OldestOpenPR:       10 * time.Hour,
+			OpenAverageTime:    10 * time.Hour,
+			AveragePRMergeTime: 2 * time.Hour,
+			MergeTimeSamples:   3,
+			AverageReviewers:   3,
+			MergedCount:        3,
+			MergedWindow:       7 * 24 * time.Hour,
+		},
+		{
+			OpenCount:          3,
+			DraftCount:         1,
+			OldestOpenPR:       40 * time.Hour,
+			OpenAverageTime:    2 * time.Hour,
+			AveragePRMergeTime: 6 * time.Hour,
+			MergeTimeSamples:   1,
+			AverageReviewers:   1,
+			MergedCount:        1,
+			MergedWindow:       7 * 24 * time.Hour,
+		},
+		{},
+	})
+
+	expected := PullRequestsInfo{
+		OpenCount:          4,
+		DraftCount:         1,
+		OldestOpenPR:       40 * time.Hour,
+		OpenAverageTime:    4 * time.Hour,
+		AveragePRMergeTime: 3 * time.Hour,
+		MergeTimeSamples:   4,
+		AverageReviewers:   1.5,
+		MergedCount:        4,
+		MergedWindow:       7 * 24 * time.Hour,
+	}
+	if aggregated != expected {
+		t.Errorf("aggregatePullRequestsInfo: Expected %+v, got %+v", expected, aggregated)
+	}
+
+	if aggregatePullRequestsInfo(nil) != (PullRequestsInfo{}) {
+		t.Errorf("aggregatePullRequestsInfo: Should be empty without repositories")
+	}
+}
+
+// repositoryErrorsProvider fails the pull request information of some
+// repositories.
+type repositoryErrorsProvider struct {
+	fakeProvider
+	errs map[string]error
+}
+
+func (provider *repositoryErrorsProvider) RetrievePullRequestInfo(ctx context.Context, request BadgeRequest) (PullRequestsInfo, error) {
+	if err := provider.errs[request.Repository]; err != nil {
+		return PullRequestsInfo{}, err
+	}
+	return PullRequestsInfo{OpenCount: 2}, nil
+}
+
+func TestWorkspaceRepositoryErrors(t *testing.T) {
+	notFound := &UpstreamError{Kind: UpstreamNotFound, StatusCode: 404}
+	unauthorized := &UpstreamError{Kind: UpstreamUnauthorized, StatusCode: 403}
+	serverError := &UpstreamError{Kind: UpstreamServerError, StatusCode: 500}
+
+	cases := []struct {
+		errs          map[string]error
+		expectedCount int
+		expectedErr   error
+	}{
+		{map[string]error{}, 4, nil},
+		{map[string]error{"repo1": notFound}, 2, nil},
+		{map[string]error{"repo2": unauthorized}, 2, nil},
+		{map[string]error{"repo1": serverError}, 0, serverError},
+		{map[string]error{"repo1": notFound, "repo2": unauthorized}, 0, unauthorized},
+	}
+
+	for _, c := range cases {
+		server := NewServer(WithProvider(&repositoryErrorsProvider{errs: c.errs}))
+		prInfo, err := server.retrieveWorkspacePullRequestInfo(context.Background(),
+			BadgeRequest{Username: "team", Repository: WorkspaceRepositories, Type: OpenPRCountType})
+
+		if err != c.expectedErr {
+			t.Errorf("retrieveWorkspacePullRequestInfo: Expected error %v for %v, got %v", c.expectedErr, c.errs, err)
+		}
+		if prInfo.OpenCount != c.expectedCount {
+			t.Errorf("retrieveWorkspacePullRequestInfo: Expected %d open PRs for %v, got %d", c.expectedCount, c.errs, prInfo.OpenCount)
+		}
+	}
+}
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 	"time"
 
-	bitbadger "github.com/Pixep/bitbadger/internal/bitbadger"
+	bitbadger "github.com/Pixep/bitbadger/bitbadger"
 	log "github.com/Sirupsen/logrus"
 	"github.com/urfave/cli"
 )
@@ -22,6 +26,34 @@ func main() {
 	app.Usage = "A badge generator for BitBucket"
 	app.Action = start
 	app.Flags = []cli.Flag{
+		cli.StringFlag{
+			Name:  "config",
+			Usage: "Path to a YAML configuration file, overridden by BITBADGER_ environment variables and flags",
+		},
+		cli.StringFlag{
+			Name:  "auth",
+			Usage: "Set the BitBucket authentication method: app-password, oauth or access-token (default: app-password)",
+		},
+		cli.StringFlag{
+			Name:  "username, u",
+			Usage: "Set the BitBucket username",
+		},
+		cli.StringFlag{
+			Name:  "passwordfile",
+			Usage: "Path to a file containing the BitBucket password",
+		},
+		cli.StringFlag{
+			Name:  "clientid",
+			Usage: "Set the key of the BitBucket OAuth consumer",
+		},
+		cli.StringFlag{
+			Name:  "clientsecretfile",
+			Usage: "Path to a file containing the secret of the BitBucket OAuth consumer",
+		},
+		cli.StringFlag{
+			Name:  "accesstokenfile",
+			Usage: "Path to a file containing a BitBucket repository, project or workspace access token",
+		},
 		cli.BoolFlag{
 			Name:  "debug, d",
 			Usage: "Enable debug mode",
@@ -43,6 +75,25 @@ func main() {
 			Usage: "Set the port that the server listens on",
 			Value: 34000,
 		},
+		cli.IntFlag{
+			Name:  "adminport",
+			Usage: "Set the port serving the metrics, status and probes, instead of the badge port",
+		},
+		cli.IntFlag{
+			Name:  "draintimeout",
+			Usage: "Set for how long pending requests are waited for when shutting down, in seconds",
+			Value: 10,
+		},
+		cli.IntFlag{
+			Name:  "calltimeout",
+			Usage: "Set the maximum duration of each request to BitBucket and shields.io, in seconds",
+			Value: 10,
+		},
+		cli.IntFlag{
+			Name:  "requesttimeout",
+			Usage: "Set the maximum duration of the generation of a badge, in seconds",
+			Value: 30,
+		},
 		cli.IntFlag{
 			Name:  "cachevalidity",
 			Usage: "Set for how long the requests should be cached in minutes",
@@ -53,6 +104,96 @@ func main() {
 			Usage: "Set the maximum number of cached requests",
 			Value: 100,
 		},
+		cli.IntFlag{
+			Name:  "longcachevalidity",
+			Usage: "Set for how long expensive requests should be cached in minutes",
+			Value: 720,
+		},
+		cli.StringSliceFlag{
+			Name:  "draftpattern",
+			Usage: "Regular expression matching draft PR titles, can be repeated (default: WIP and Draft prefixes)",
+		},
+		cli.BoolFlag{
+			Name:  "excludedrafts",
+			Usage: "Exclude draft PRs from open PR metrics",
+		},
+		cli.IntFlag{
+			Name:  "throughputwindow",
+			Usage: "Set the rolling window used for merged PR throughput, in days",
+			Value: 28,
+		},
+		cli.IntFlag{
+			Name:  "stalebranchage",
+			Usage: "Set the age in days after which a branch without open PR is stale",
+			Value: 90,
+		},
+		cli.IntFlag{
+			Name:  "contributorswindow",
+			Usage: "Set the rolling window used for contributor metrics, in days",
+			Value: 90,
+		},
+		cli.IntFlag{
+			Name:  "workers",
+			Usage: "Set the maximum number of repositories queried concurrently for workspace badges",
+			Value: 4,
+		},
+		cli.IntFlag{
+			Name:  "retries",
+			Usage: "Set how many times requests failing with a temporary BitBucket error are retried",
+			Value: bitbadger.DefaultRetryPolicy.MaxRetries,
+		},
+		cli.IntFlag{
+			Name:  "ratelimit",
+			Usage: "Set the maximum number of requests per hour sent to BitBucket, 0 to disable",
+			Value: bitbadger.DefaultRateLimitPolicy.RequestsPerHour,
+		},
+		cli.IntFlag{
+			Name:  "rateburst",
+			Usage: "Set the number of requests which can be sent to BitBucket at once",
+			Value: bitbadger.DefaultRateLimitPolicy.Burst,
+		},
+		cli.IntFlag{
+			Name:  "breakerthreshold",
+			Usage: "Set the number of consecutive BitBucket failures after which requests stop being sent, 0 to disable",
+			Value: bitbadger.DefaultCircuitBreakerPolicy.FailureThreshold,
+		},
+		cli.IntFlag{
+			Name:  "breakerduration",
+			Usage: "Set for how long requests stop being sent to BitBucket after consecutive failures, in seconds",
+			Value: int(bitbadger.DefaultCircuitBreakerPolicy.OpenDuration / time.Second),
+		},
+		cli.StringFlag{
+			Name:  "healthconfig",
+			Usage: "Path to a YAML file setting the health score weights and targets",
+		},
+		cli.StringFlag{
+			Name:  "durationformat",
+			Usage: "Set the default format of durations: long, compact or iso8601",
+			Value: "long",
+		},
+		cli.BoolFlag{
+			Name:  "businesshours",
+			Usage: "Compute PR ages and merge times in working hours only",
+		},
+		cli.StringFlag{
+			Name:  "timezone",
+			Usage: "Set the timezone of working hours",
+			Value: "UTC",
+		},
+		cli.StringFlag{
+			Name:  "workdays",
+			Usage: "Set the days of the work week",
+			Value: "mon,tue,wed,thu,fri",
+		},
+		cli.StringFlag{
+			Name:  "workhours",
+			Usage: "Set the daily working hours",
+			Value: "09:00-17:00",
+		},
+		cli.StringFlag{
+			Name:  "holidays",
+			Usage: "Path to a file listing non-working days, one YYYY-MM-DD date per line",
+		},
 	}
 
 	err := app.Run(os.Args)
@@ -65,42 +206,138 @@ func main() {
 }
 
 func start(c *cli.Context) error {
-	if c.NArg() < 2 {
-		log.Fatal("Please provide a Username and Password.")
+	config := bitbadger.DefaultServerConfig()
+	if path := c.String("config"); path != "" {
+		var err error
+		config, err = bitbadger.LoadServerConfig(path)
+		if err != nil {
+			return errors.New("Invalid configuration file: " + err.Error())
+		}
 	}
 
-	if c.Bool("debug") {
+	err := config.ApplyEnvironment(os.Environ())
+	if err != nil {
+		return err
+	}
+
+	applyFlags(c, &config)
+
+	if c.NArg() >= 2 {
+		log.Warn("Passing the username and password as arguments is deprecated, " +
+			"as they are visible to other users of the host: use a configuration file, " +
+			"BITBADGER_CREDENTIALS_PASSWORD_FILE or --passwordfile instead")
+		config.Credentials.Auth = string(bitbadger.AppPasswordAuth)
+		config.Credentials.Username = c.Args().Get(0)
+		config.Credentials.Password = c.Args().Get(1)
+		config.Credentials.PasswordFile = ""
+	}
+
+	err = config.Validate()
+	if err != nil {
+		return err
+	}
+
+	if config.Debug {
 		log.SetLevel(log.DebugLevel)
 	}
 
-	config := bitbadger.Config{
-		Username: c.Args().Get(0),
-		Password: c.Args().Get(1),
+	options, err := config.Apply()
+	if err != nil {
+		return err
 	}
-	bitbadger.SetConfig(config)
 
-	bitbadger.SetCachePolicy(bitbadger.CachePolicy{
-		ValidityDuration: time.Duration(c.Int("cachevalidity")) * time.Minute,
-		MaxCachedResults: c.Int("maxcached"),
-	})
+	server := bitbadger.NewServer(options...)
 
-	log.Info("Serving badges as '", config.Username, "'")
+	switch {
+	case config.Credentials.Empty():
+		log.Info("Serving badges only for the workspaces of ", len(config.CredentialRoutes), " credential routes")
+	case bitbadger.AuthMethod(config.Credentials.Auth) == bitbadger.OAuthAuth:
+		log.Info("Serving badges as OAuth consumer '", config.Credentials.ClientID, "'")
+	case bitbadger.AuthMethod(config.Credentials.Auth) == bitbadger.AccessTokenAuth:
+		log.Info("Serving badges with an access token")
+	default:
+		log.Info("Serving badges as '", config.Credentials.Username, "'")
+	}
 
-	if c.Bool("insecure") {
+	ctx := shutdownContext()
+	addr := ":" + strconv.Itoa(config.Listen.Port)
+
+	if config.Listen.Insecure {
 		log.Info("Running in HTTP-mode")
-		return bitbadger.ServeWithHTTP(c.Int("port"))
+		return server.ListenAndServe(ctx, addr)
 	}
 
-	certFile := c.String("cert")
-	if certFile == "" {
-		log.Error("No certificate provided.")
-		return errors.New("No certificate was provided")
+	return server.ListenAndServeTLS(ctx, addr, config.Listen.TLS.Cert, config.Listen.TLS.Key)
+}
+
+// shutdownContext returns a context which is done once SIGTERM or SIGINT is
+// received.
+func shutdownContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	go func() {
+		receivedSignal := <-signals
+		log.Info("Received ", receivedSignal, ", shutting down")
+		cancel()
+	}()
+
+	return ctx
+}
+
+// applyFlags overrides the configuration with the flags set on the command
+// line.
+func applyFlags(c *cli.Context, config *bitbadger.ServerConfig) {
+	seconds := func(name string) bitbadger.Duration {
+		return bitbadger.Duration(time.Duration(c.Int(name)) * time.Second)
+	}
+	minutes := func(name string) bitbadger.Duration {
+		return bitbadger.Duration(time.Duration(c.Int(name)) * time.Minute)
 	}
 
-	keyFile := c.String("key")
-	if keyFile == "" {
-		return errors.New("No private key was provided")
+	setters := map[string]func(){
+		"debug":              func() { config.Debug = c.Bool("debug") },
+		"auth":               func() { config.Credentials.Auth = c.String("auth") },
+		"username":           func() { config.Credentials.Username = c.String("username") },
+		"passwordfile":       func() { config.Credentials.PasswordFile = c.String("passwordfile") },
+		"clientid":           func() { config.Credentials.ClientID = c.String("clientid") },
+		"clientsecretfile":   func() { config.Credentials.ClientSecretFile = c.String("clientsecretfile") },
+		"accesstokenfile":    func() { config.Credentials.AccessTokenFile = c.String("accesstokenfile") },
+		"insecure":           func() { config.Listen.Insecure = c.Bool("insecure") },
+		"cert":               func() { config.Listen.TLS.Cert = c.String("cert") },
+		"key":                func() { config.Listen.TLS.Key = c.String("key") },
+		"port":               func() { config.Listen.Port = c.Int("port") },
+		"adminport":          func() { config.Listen.AdminPort = c.Int("adminport") },
+		"draintimeout":       func() { config.Listen.DrainTimeout = seconds("draintimeout") },
+		"requesttimeout":     func() { config.Listen.RequestTimeout = seconds("requesttimeout") },
+		"calltimeout":        func() { config.Provider.CallTimeout = seconds("calltimeout") },
+		"retries":            func() { config.Provider.Retries = c.Int("retries") },
+		"ratelimit":          func() { config.Provider.RateLimit = c.Int("ratelimit") },
+		"rateburst":          func() { config.Provider.RateBurst = c.Int("rateburst") },
+		"breakerthreshold":   func() { config.Provider.BreakerThreshold = c.Int("breakerthreshold") },
+		"breakerduration":    func() { config.Provider.BreakerDuration = seconds("breakerduration") },
+		"cachevalidity":      func() { config.Cache.Validity = minutes("cachevalidity") },
+		"maxcached":          func() { config.Cache.MaxCached = c.Int("maxcached") },
+		"longcachevalidity":  func() { config.Cache.LongValidity = minutes("longcachevalidity") },
+		"draftpattern":       func() { config.Policies.DraftPatterns = c.StringSlice("draftpattern") },
+		"excludedrafts":      func() { config.Policies.ExcludeDrafts = c.Bool("excludedrafts") },
+		"throughputwindow":   func() { config.Policies.ThroughputWindowDays = c.Int("throughputwindow") },
+		"stalebranchage":     func() { config.Policies.StaleBranchAgeDays = c.Int("stalebranchage") },
+		"contributorswindow": func() { config.Policies.ContributorsWindowDays = c.Int("contributorswindow") },
+		"workers":            func() { config.Policies.Workers = c.Int("workers") },
+		"healthconfig":       func() { config.Policies.HealthConfig = c.String("healthconfig") },
+		"durationformat":     func() { config.Policies.DurationFormat = c.String("durationformat") },
+		"businesshours":      func() { config.Policies.BusinessHours.Enabled = c.Bool("businesshours") },
+		"timezone":           func() { config.Policies.BusinessHours.Timezone = c.String("timezone") },
+		"workdays":           func() { config.Policies.BusinessHours.WorkDays = c.String("workdays") },
+		"workhours":          func() { config.Policies.BusinessHours.WorkHours = c.String("workhours") },
+		"holidays":           func() { config.Policies.BusinessHours.HolidaysFile = c.String("holidays") },
 	}
 
-	return bitbadger.ServeWithHTTPS(c.Int("port"), certFile, keyFile)
+	for name, set := range setters {
+		if c.IsSet(name) {
+			set()
+		}
+	}
 }